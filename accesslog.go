@@ -0,0 +1,150 @@
+package xslog
+
+import (
+	"io"
+	"log/slog"
+	"strconv"
+)
+
+// 访问日志使用的属性名，xsloghttp 中间件按这些名称记录请求
+const (
+	HTTPMethodKey    = "method"
+	HTTPPathKey      = "path"
+	HTTPProtoKey     = "proto"
+	HTTPStatusKey    = "status"
+	HTTPBytesKey     = "bytes"
+	HTTPLatencyKey   = "latency"
+	HTTPClientIPKey  = "client_ip"
+	HTTPUserKey      = "user"
+	HTTPRefererKey   = "referer"
+	HTTPUserAgentKey = "user_agent"
+)
+
+// AccessLogStyle 是 AccessLogFormat 输出的行格式
+type AccessLogStyle int
+
+const (
+	// AccessLogCombined 是 Apache Combined 格式：
+	// 127.0.0.1 - bob [10/Oct/2000:13:55:36 -0700] "GET /a HTTP/1.1" 200 2326 "referer" "user agent"
+	AccessLogCombined AccessLogStyle = iota
+	// AccessLogCommon 是 Apache Common 格式，即不带 referer 和 user agent 的 Combined
+	AccessLogCommon
+	// AccessLogNginx 是 Combined 之后加上以秒为单位的处理时间，与 nginx 常用的 $request_time 相同
+	AccessLogNginx
+)
+
+// AccessLogFormat 把带 HTTP*Key 属性的记录（如 xsloghttp 记录的请求）输出为访问日志行，
+// 缺少的字段输出为 "-"，其他属性和消息不输出；没有 method 属性的记录不是请求，直接跳过
+//
+//	xslog.Output{Writer: accessFile, Format: xslog.AccessLogFormat{Style: xslog.AccessLogNginx}}
+type AccessLogFormat struct {
+	Style AccessLogStyle
+}
+
+// 访问日志中的时间格式
+const accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+func (f AccessLogFormat) encoder(w io.Writer) slog.Handler {
+	return &flatHandler{w: w, format: func(buf []byte, r slog.Record, attrs []flatAttr) []byte {
+		field := func(key string) (slog.Value, bool) {
+			for i := len(attrs) - 1; i >= 0; i-- {
+				if attrs[i].key == key {
+					return attrs[i].value, true
+				}
+			}
+			return slog.Value{}, false
+		}
+		str := func(buf []byte, key string) []byte {
+			if v, ok := field(key); ok && v.String() != "" {
+				return appendAccessLogField(buf, flatValue(v))
+			}
+			return append(buf, '-')
+		}
+		quoted := func(buf []byte, key string) []byte {
+			buf = append(buf, '"')
+			if v, ok := field(key); ok && v.String() != "" {
+				buf = appendAccessLogQuoted(buf, flatValue(v))
+			} else {
+				buf = append(buf, '-')
+			}
+			return append(buf, '"')
+		}
+
+		if _, ok := field(HTTPMethodKey); !ok {
+			return buf
+		}
+		buf = str(buf, HTTPClientIPKey)
+		buf = append(buf, " - "...)
+		buf = str(buf, HTTPUserKey)
+		buf = append(buf, " ["...)
+		buf = r.Time.AppendFormat(buf, accessLogTimeFormat)
+		buf = append(buf, `] "`...)
+		buf = appendAccessLogQuoted(buf, accessLogRequest(field))
+		buf = append(buf, `" `...)
+		buf = str(buf, HTTPStatusKey)
+		buf = append(buf, ' ')
+		if v, ok := field(HTTPBytesKey); ok && v.String() != "0" && v.String() != "" {
+			buf = appendAccessLogField(buf, flatValue(v))
+		} else {
+			buf = append(buf, '-')
+		}
+		if f.Style != AccessLogCommon {
+			buf = append(buf, ' ')
+			buf = quoted(buf, HTTPRefererKey)
+			buf = append(buf, ' ')
+			buf = quoted(buf, HTTPUserAgentKey)
+		}
+		if f.Style == AccessLogNginx {
+			buf = append(buf, ' ')
+			if v, ok := field(HTTPLatencyKey); ok && v.Kind() == slog.KindDuration {
+				buf = strconv.AppendFloat(buf, v.Duration().Seconds(), 'f', 3, 64)
+			} else {
+				buf = append(buf, '-')
+			}
+		}
+		return append(buf, '\n')
+	}}
+}
+
+// accessLogRequest 返回请求行，如 "GET /a HTTP/1.1"
+func accessLogRequest(field func(string) (slog.Value, bool)) string {
+	method, ok1 := field(HTTPMethodKey)
+	path, ok2 := field(HTTPPathKey)
+	if !ok1 || !ok2 {
+		return "-"
+	}
+	line := method.String() + " " + path.String()
+	if proto, ok := field(HTTPProtoKey); ok {
+		line += " " + proto.String()
+	}
+	return line
+}
+
+// appendAccessLogField 转义不带引号的字段（client_ip、user 等），除了 appendAccessLogQuoted 转义的字符之外
+// 空格也写成 \x20，不可信的值不会打乱以空格分隔的字段
+func appendAccessLogField(buf []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' {
+			buf = appendAccessLogQuoted(buf, s[start:i])
+			buf = appendEscapedByte(buf, ' ')
+			start = i + 1
+		}
+	}
+	return appendAccessLogQuoted(buf, s[start:])
+}
+
+// appendAccessLogQuoted 按 Apache 的做法转义引号内的 `"`、`\` 和控制字符
+func appendAccessLogQuoted(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			buf = append(buf, '\\', c)
+		case c < 0x20 || c == 0x7f:
+			buf = appendEscapedByte(buf, c)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
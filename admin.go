@@ -0,0 +1,157 @@
+package xslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+)
+
+// adminState 是 AdminHandler 读写的 JSON 结构，PUT 时未出现的字段保持不变
+type adminState struct {
+	Console *adminSink        `json:"console,omitempty"`
+	File    *adminSink        `json:"file,omitempty"`
+	Levels  map[string]string `json:"levels,omitempty"`
+}
+
+type adminSink struct {
+	Enabled *bool   `json:"enabled,omitempty"`
+	Level   *string `json:"level,omitempty"`
+	Path    *string `json:"path,omitempty"`
+}
+
+// AdminHandler 返回用于运行时查看和调整 logger 的 http.Handler：
+//
+//	GET  返回各输出的开关、级别和 levels 规则
+//	PUT  修改其中的部分字段，如 {"console":{"level":"debug"}}、{"file":{"enabled":false}}
+//	     或 {"levels":{"db.*":"debug"}}（level 为空字符串时删除该规则）
+//
+// 该接口可以改变日志输出，挂载时应自行做好访问控制
+func (ml *Logger) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req adminState
+			dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil {
+				http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := ml.applyAdmin(req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ml.adminSnapshot())
+	})
+}
+
+func (ml *Logger) adminSnapshot() adminState {
+	ml.mu.Lock()
+	consoleOn, fileOn, path := ml.config.LogToConsole, ml.config.LogToFile, ml.config.LogFilePath
+	ml.mu.Unlock()
+	consoleLevel, fileLevel := levelString(ml.GetConsoleLevel()), levelString(ml.GetFileLevel())
+
+	state := adminState{
+		Console: &adminSink{Enabled: &consoleOn, Level: &consoleLevel},
+		File:    &adminSink{Enabled: &fileOn, Level: &fileLevel, Path: &path},
+		Levels:  map[string]string{},
+	}
+	for _, rule := range ml.levelRules.list() {
+		state.Levels[rule.pattern] = levelString(rule.level)
+	}
+	return state
+}
+
+// applyAdmin 先校验全部字段，再依次应用，避免只应用了一半
+func (ml *Logger) applyAdmin(req adminState) error {
+	var consoleLevel, fileLevel *slog.Level
+	parse := func(key string, s *string) (*slog.Level, error) {
+		if s == nil {
+			return nil, nil
+		}
+		level, err := ParseLevel(*s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		return &level, nil
+	}
+	var err error
+	if req.Console != nil {
+		if req.Console.Path != nil {
+			return fmt.Errorf("console.path: unknown field")
+		}
+		if consoleLevel, err = parse("console.level", req.Console.Level); err != nil {
+			return err
+		}
+	}
+	if req.File != nil {
+		if fileLevel, err = parse("file.level", req.File.Level); err != nil {
+			return err
+		}
+	}
+	rules := map[string]*slog.Level{}
+	for _, pattern := range sortedKeys(req.Levels) {
+		value := req.Levels[pattern]
+		if value == "" {
+			rules[pattern] = nil
+			continue
+		}
+		if rules[pattern], err = parse("levels."+pattern, &value); err != nil {
+			return err
+		}
+	}
+
+	if consoleLevel != nil {
+		ml.SetConsoleLevel(*consoleLevel)
+	}
+	if req.Console != nil && req.Console.Enabled != nil {
+		ml.EnableConsole(*req.Console.Enabled)
+	}
+	if fileLevel != nil {
+		ml.SetFileLevel(*fileLevel)
+	}
+	if req.File != nil && req.File.Path != nil {
+		if err := ml.ChangeFilePath(*req.File.Path); err != nil {
+			return fmt.Errorf("file.path: %w", err)
+		}
+	}
+	if req.File != nil && req.File.Enabled != nil {
+		if err := ml.EnableFile(*req.File.Enabled); err != nil {
+			return fmt.Errorf("file.enabled: %w", err)
+		}
+	}
+	for pattern, level := range rules {
+		if level == nil {
+			ml.ClearLevelFor(pattern)
+		} else {
+			ml.SetLevelFor(pattern, *level)
+		}
+	}
+	return nil
+}
+
+// levelString 返回注册过的级别名称，未注册时使用 slog 的写法（如 "INFO+2"）
+func levelString(level slog.Level) string {
+	if spec, ok := lookupLevel(level); ok {
+		return spec.Name
+	}
+	return level.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,401 @@
+package xslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AsyncConfig 的默认值
+const (
+	defaultAsyncQueueSize     = 1024
+	defaultAsyncFlushInterval = 100 * time.Millisecond
+)
+
+// AsyncConfig 配置异步输出
+type AsyncConfig struct {
+	// Enabled 为 true 时，记录先进入队列，由每个输出各自的后台 goroutine 写出，
+	// 调用方不再等待磁盘 I/O。程序退出前需要调用 Close，否则队列中的记录可能丢失。
+	// 记录中的属性值会在后台 goroutine 中读取，记录后不要再修改它们
+	Enabled   bool
+	QueueSize int // 每个输出的队列长度，默认 1024；队列满时调用方阻塞等待
+
+	// BatchSize 大于 1 时，最多攒够这么多条记录再一次性写出，减少系统调用；
+	// 攒不够时最多等待 FlushInterval（默认 100ms）就写出
+	BatchSize     int
+	FlushInterval time.Duration
+
+	// Overflow 决定队列满时的行为，默认阻塞调用方；丢弃的记录数可以通过 Logger.Dropped 查看
+	Overflow OverflowPolicy
+}
+
+// OverflowPolicy 是异步队列满时的处理策略
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // 阻塞调用方直到队列有空位
+	OverflowDropNewest                       // 丢弃新记录
+	OverflowDropOldest                       // 丢弃队列中最旧的记录，为新记录腾出位置
+)
+
+// asyncItem 是队列中的一条记录
+type asyncItem struct {
+	h     slog.Handler
+	ctx   context.Context
+	r     slog.Record
+	force bool // 命中级别覆盖规则
+}
+
+// asyncWorker 持有一个输出的队列和后台 goroutine
+type asyncWorker struct {
+	mu       sync.RWMutex // 保护 closed，避免向已关闭的队列发送
+	closed   bool
+	queue    chan asyncItem
+	flushReq chan chan struct{} // flush 请求，与记录分开，丢弃旧记录时不会误丢
+	done     chan struct{}
+	overflow OverflowPolicy
+	dropped  atomic.Uint64
+	self     *selfLog // 丢弃记录时提醒
+
+	batches       []*batchWriter // 为空时不合并写入
+	batchSize     int
+	flushInterval time.Duration
+}
+
+func newAsyncWorker(cfg AsyncConfig, batches []*batchWriter) *asyncWorker {
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultAsyncQueueSize
+	}
+	w := &asyncWorker{
+		queue:         make(chan asyncItem, size),
+		flushReq:      make(chan chan struct{}),
+		done:          make(chan struct{}),
+		overflow:      cfg.Overflow,
+		batches:       batches,
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = defaultAsyncFlushInterval
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWorker) run() {
+	defer close(w.done)
+
+	var (
+		pending int
+		timer   *time.Timer
+		timeout <-chan time.Time
+	)
+	flush := func() {
+		if pending > 0 {
+			for _, b := range w.batches {
+				_ = b.flush() // 错误已由 flush 报告给 OnError
+			}
+		}
+		pending = 0
+		if timer != nil {
+			timer.Stop()
+		}
+		timeout = nil
+	}
+	handle := func(item asyncItem) {
+		_ = handleRecord(item.ctx, item.h, item.r, item.force)
+		if len(w.batches) == 0 {
+			return
+		}
+		pending++
+		if pending >= w.batchSize {
+			flush()
+		} else if timeout == nil {
+			// 从攒下第一条记录开始计时，保证最大延迟
+			timer = time.NewTimer(w.flushInterval)
+			timeout = timer.C
+		}
+	}
+
+	for {
+		select {
+		case item, ok := <-w.queue:
+			if !ok {
+				flush()
+				for _, b := range w.batches {
+					b.passthrough()
+				}
+				return
+			}
+			handle(item)
+		case ack := <-w.flushReq:
+			// 先写完请求之前已经在队列中的记录
+		drain:
+			for {
+				select {
+				case item, ok := <-w.queue:
+					if !ok {
+						break drain
+					}
+					handle(item)
+				default:
+					break drain
+				}
+			}
+			flush()
+			close(ack)
+		case <-timeout:
+			flush()
+		}
+	}
+}
+
+// enqueue 按 overflow 策略把记录放入队列；队列已关闭时返回 false，由调用方同步写出
+func (w *asyncWorker) enqueue(item asyncItem) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	switch w.overflow {
+	case OverflowDropNewest:
+		select {
+		case w.queue <- item:
+		default:
+			w.drop()
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.queue <- item:
+				return true
+			default:
+			}
+			select {
+			case <-w.queue:
+				w.drop()
+			default:
+			}
+		}
+	default:
+		w.queue <- item
+	}
+	return true
+}
+
+// drop 统计一条被丢弃的记录
+func (w *asyncWorker) drop() {
+	w.dropped.Add(1)
+	w.self.printf("queue full", "async queue full, records dropped (%d so far)", w.dropped.Load())
+}
+
+// flush 等待此前进入队列的记录全部写出
+func (w *asyncWorker) flush() {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	ack := make(chan struct{})
+	w.flushReq <- ack
+	<-ack
+}
+
+// close 停止接收新记录，等待队列中的记录写完
+// ctx 结束时不再等待，返回 false 和仍未写出的记录数
+func (w *asyncWorker) close(ctx context.Context) (pending int, ok bool) {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+		return 0, true
+	case <-ctx.Done():
+		return len(w.queue), false
+	}
+}
+
+// asyncHandler 把 Handle 转交给后台 goroutine，派生出的 handler 共用同一个队列
+type asyncHandler struct {
+	w     *asyncWorker
+	inner slog.Handler
+}
+
+func (h *asyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *asyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handle(ctx, r, false)
+}
+
+func (h *asyncHandler) handleForce(ctx context.Context, r slog.Record) error {
+	return h.handle(ctx, r, true)
+}
+
+func (h *asyncHandler) handle(ctx context.Context, r slog.Record, force bool) error {
+	if h.w.enqueue(asyncItem{h: h.inner, ctx: ctx, r: r.Clone(), force: force}) {
+		return nil
+	}
+	return handleRecord(ctx, h.inner, r, force)
+}
+
+func (h *asyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &asyncHandler{w: h.w, inner: h.inner.WithAttrs(attrs)}
+}
+
+func (h *asyncHandler) WithGroup(name string) slog.Handler {
+	return &asyncHandler{w: h.w, inner: h.inner.WithGroup(name)}
+}
+
+// async 用 newHandler 创建写入 dsts 的输出，启用异步时为它创建后台 goroutine，
+// 需要合并写入时 handler 先写入缓冲，由后台 goroutine 批量写到各个 dst；
+// sinks 是各个 dst 在 OnError 中的名称，批量写入失败时按这个名称报告
+func (ml *Logger) async(sinks []string, dsts []io.Writer, newHandler func([]io.Writer) slog.Handler) slog.Handler {
+	cfg := ml.config.Async
+	if !cfg.Enabled {
+		return newHandler(dsts)
+	}
+	var batches []*batchWriter
+	if cfg.BatchSize > 1 {
+		wrapped := make([]io.Writer, len(dsts))
+		for i, dst := range dsts {
+			b := &batchWriter{dst: dst, sink: sinks[i], report: &ml.sinkErrors}
+			batches = append(batches, b)
+			wrapped[i] = b
+		}
+		dsts = wrapped
+	}
+	w := newAsyncWorker(cfg, batches)
+	w.self = ml.self
+	ml.workers = append(ml.workers, w)
+	return &asyncHandler{w: w, inner: newHandler(dsts)}
+}
+
+// batchWriter 缓存 handler 写出的内容，由后台 goroutine 一次性写到 dst；
+// 后台 goroutine 退出后直接写到 dst。handler 写入缓冲总是成功，
+// 写到 dst 的错误由 flush 报告给 report
+type batchWriter struct {
+	mu     sync.Mutex
+	dst    io.Writer
+	buf    bytes.Buffer
+	direct bool
+	sink   string
+	report *errorReporter
+}
+
+func (b *batchWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.direct {
+		return b.dst.Write(p)
+	}
+	return b.buf.Write(p)
+}
+
+func (b *batchWriter) flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	_, err := b.dst.Write(b.buf.Bytes())
+	b.buf.Reset()
+	b.report.report(b.sink, err)
+	return err
+}
+
+func (b *batchWriter) passthrough() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.direct = true
+}
+
+// flushWorkers 等待此前进入异步队列的记录全部写出
+func (ml *Logger) flushWorkers() {
+	for _, w := range ml.workers {
+		w.flush()
+	}
+}
+
+// Flush 让所有输出写完缓冲和异步队列中的记录，并把日志文件刷到磁盘（fsync），
+// 适合在退出前、检查点或记录严重错误后调用
+func (ml *Logger) Flush() error {
+	ml.limiter.flush()
+	ml.deduper.flush()
+	ml.flushWorkers()
+	err := ml.fileWriter.Sync()
+	if ml.auditWriter != nil {
+		err = errors.Join(err, ml.auditWriter.Sync())
+	}
+	return err
+}
+
+// Sync 与 Flush 相同，便于从 zap 等库迁移
+func (ml *Logger) Sync() error {
+	return ml.Flush()
+}
+
+// QueueDepth 返回异步队列中等待写出的记录总数，同步模式下为 0
+func (ml *Logger) QueueDepth() int {
+	n := 0
+	for _, w := range ml.workers {
+		n += len(w.queue)
+	}
+	return n
+}
+
+// Dropped 返回异步队列满时按 Overflow 策略丢弃的记录总数
+func (ml *Logger) Dropped() uint64 {
+	var n uint64
+	for _, w := range ml.workers {
+		n += w.dropped.Load()
+	}
+	return n
+}
+
+// stopWorkers 等待所有异步队列写完并停止后台 goroutine，ctx 结束时放弃等待
+func (ml *Logger) stopWorkers(ctx context.Context) error {
+	pending, timedOut := 0, false
+	for _, w := range ml.workers {
+		n, ok := w.close(ctx)
+		pending += n
+		timedOut = timedOut || !ok
+	}
+	if timedOut {
+		return fmt.Errorf("log shutdown: %d records not written: %w", pending, ctx.Err())
+	}
+	return nil
+}
+
+// SetShutdownTimeout 设置 Close 等待异步队列写完的最长时间，0 表示一直等待
+func (ml *Logger) SetShutdownTimeout(d time.Duration) {
+	ml.shutdownTimeout.Store(int64(d))
+}
+
+// Shutdown 与 Close 相同，但最多等待到 ctx 结束；超时后仍会关闭日志文件，
+// 并返回包含未写出记录数的错误
+func (ml *Logger) Shutdown(ctx context.Context) error {
+	ml.limiter.flush()
+	ml.deduper.flush()
+	err := ml.stopWorkers(ctx)
+	if ml.fileWriter != nil {
+		ml.fileWriter.stopTicker()
+		err = errors.Join(err, ml.fileWriter.Close())
+	}
+	if ml.auditWriter != nil {
+		ml.auditWriter.stopTicker()
+		err = errors.Join(err, ml.auditWriter.Close())
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package xslog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime"
+)
+
+// SinkAudit 是审计输出在 OnError 回调中的名称
+const SinkAudit = "audit"
+
+// AuditConfig 配置 Logger.Audit 使用的独立输出。审计记录不经过级别过滤、采样、限流、
+// 错误风暴抑制、合并重复记录和大小限制，只做脱敏（Redact、Scrub 和 PII）。
+// 审计文件与日志文件分开，可以用 Rotation 单独设置轮转和保留期限
+type AuditConfig struct {
+	Path   string    // 审计文件路径，以追加方式打开
+	Writer io.Writer // Path 为空时写入 Writer，由调用方负责关闭
+
+	// Rotation 不为 nil 时审计文件按它轮转和清理旧文件，与 LogConfig.Rotation 互不影响，见 RotationConfig
+	Rotation *RotationConfig
+
+	// Sync 决定审计文件何时 fsync，零值时每条记录都 fsync
+	Sync SyncPolicy
+
+	// HashChain 和 SigningKey 见 LogConfig.FileHashChain 和 LogConfig.FileSigningKey，只能与 Path 一起使用
+	HashChain  bool
+	SigningKey ed25519.PrivateKey
+	PII        PIIPolicy // 如何处理 PII 标记的属性，默认 PIIRedact
+}
+
+// auditSink 是 MultiHandler 中的审计输出，只接收 Logger.Audit 的记录；
+// 放在 MultiHandler 中是为了让 With 附加的属性同样出现在审计记录中
+type auditSink struct {
+	inner slog.Handler
+}
+
+func (s *auditSink) Enabled(context.Context, slog.Level) bool { return false }
+
+func (s *auditSink) Handle(ctx context.Context, r slog.Record) error {
+	return s.inner.Handle(ctx, r)
+}
+
+func (s *auditSink) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &auditSink{inner: s.inner.WithAttrs(attrs)}
+}
+
+func (s *auditSink) WithGroup(name string) slog.Handler {
+	return &auditSink{inner: s.inner.WithGroup(name)}
+}
+
+// newAuditSink 按配置打开审计输出，返回的 fileWriter 在写入 Writer 时为 nil
+func (ml *Logger) newAuditSink(cfg *AuditConfig) (*auditSink, *fileWriter, error) {
+	w := cfg.Writer
+	var fw *fileWriter
+	if cfg.Path != "" {
+		sync := cfg.Sync
+		if sync == (SyncPolicy{}) {
+			sync.Every = 1
+		}
+		fw = newFileWriter(LogConfig{FileSync: sync, FileHashChain: cfg.HashChain, FileSigningKey: cfg.SigningKey, Rotation: cfg.Rotation})
+		fw.report = func(err error) { ml.sinkErrors.report(SinkAudit, err) }
+		fw.self = ml.self
+		file, err := openLogFile(cfg.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		fw.swap(file)
+		fw.start()
+		w = fw
+	} else if w == nil {
+		return nil, nil, errors.New("audit: Path or Writer is required")
+	} else if cfg.HashChain || cfg.SigningKey != nil || cfg.Rotation != nil {
+		return nil, nil, errors.New("audit: HashChain, SigningKey and Rotation require Path")
+	}
+	dest := &jsonDest{name: SinkAudit, level: slog.Level(-1 << 31), w: w, pii: cfg.PII}
+	return &auditSink{inner: newJSONSink([]*jsonDest{dest}, &ml.sinkErrors)}, fw, nil
+}
+
+// Audit 把安全相关的事件（登录、权限变更等）写到 LogConfig.Audit 配置的独立输出，
+// 不受级别和各种降噪功能影响，不会写到控制台和日志文件。没有配置审计输出时按 Info 正常记录
+func (ml *Logger) Audit(msg string, args ...any) {
+	ml.audit(context.Background(), msg, args...)
+}
+
+// AuditContext 与 Audit 相同，并附加 ctx 中的属性
+func (ml *Logger) AuditContext(ctx context.Context, msg string, args ...any) {
+	ml.audit(ctx, msg, args...)
+}
+
+func (ml *Logger) audit(ctx context.Context, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// 调用栈：0 runtime.Callers, 1 audit, 2 Audit 等公开方法, 3 调用方
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(ml.now(), slog.LevelInfo, msg, pcs[0])
+	r.Add(args...)
+
+	sink := ml.handler.auditSink()
+	if sink == nil {
+		_ = ml.handle(ctx, r)
+		return
+	}
+	if ml.name != "" {
+		r.AddAttrs(slog.String(LoggerNameKey, ml.name))
+	}
+	r.AddAttrs(ml.contextAttrs(ctx)...)
+	_ = sink.Handle(ctx, ml.transformRecord(r))
+}
@@ -0,0 +1,91 @@
+package xslog
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// BreakerConfig 的默认值
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen 是熔断期间 CircuitBreaker.Write 返回的错误
+var ErrCircuitOpen = errors.New("log output circuit open")
+
+// BreakerConfig 配置 CircuitBreaker
+type BreakerConfig struct {
+	Threshold int           // 连续失败多少次后熔断，默认 5
+	Cooldown  time.Duration // 熔断持续时间，之后放行一次写入试探，默认 30s
+}
+
+// CircuitBreaker 包装一个输出，连续失败 Threshold 次后在 Cooldown 内直接返回 ErrCircuitOpen，
+// 不再调用底层输出，避免已经不可用的远程服务拖慢每一次写日志。
+// Cooldown 结束后的第一次写入作为试探：成功则恢复，失败则继续熔断。
+// 配合 LogConfig.Fallback 使用时，熔断期间的记录写到备用输出
+type CircuitBreaker struct {
+	w   io.Writer
+	cfg BreakerConfig
+
+	mu       sync.Mutex
+	failures int
+	openTill time.Time // 零值表示未熔断
+	probing  bool      // 试探写入正在进行
+}
+
+func NewCircuitBreaker(w io.Writer, cfg BreakerConfig) *CircuitBreaker {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultBreakerThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultBreakerCooldown
+	}
+	return &CircuitBreaker{w: w, cfg: cfg}
+}
+
+func (b *CircuitBreaker) Write(p []byte) (int, error) {
+	if !b.allow() {
+		return 0, ErrCircuitOpen
+	}
+	n, err := b.w.Write(p)
+	b.done(err)
+	return n, err
+}
+
+// Open 报告当前是否处于熔断状态
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openTill.IsZero()
+}
+
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openTill.IsZero() {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openTill) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *CircuitBreaker) done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		b.openTill = time.Time{}
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.Threshold {
+		b.openTill = time.Now().Add(b.cfg.Cooldown)
+	}
+}
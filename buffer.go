@@ -0,0 +1,27 @@
+package xslog
+
+import "sync"
+
+// 超过这个容量的缓冲不放回池中，避免偶尔的大记录长期占用内存
+const maxPooledBuffer = 16 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// getBuffer 从池中取出一个空缓冲
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer 把缓冲放回池中
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
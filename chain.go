@@ -0,0 +1,138 @@
+package xslog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// PrevHashKey 是哈希链模式下每条记录中保存上一条记录哈希的字段名
+const PrevHashKey = "prev_hash"
+
+// ErrChainBroken 表示日志文件的哈希链校验失败：记录被修改、删除、插入或重新排序
+var ErrChainBroken = errors.New("hash chain broken")
+
+// 文件中第一条记录的 prev_hash
+var genesisHash = bytes.Repeat([]byte("0"), sha256.Size*2)
+
+// hashChain 为写入文件的每条记录加上上一条记录（整行，不含换行）的 SHA-256，
+// 任何一条记录被改动都会让后面的链接对不上
+type hashChain struct {
+	prev []byte // 上一条记录哈希的十六进制，为空时表示还没有记录
+	line []byte
+}
+
+// link 返回在 p 的 JSON 对象开头插入 prev_hash 后的记录；p 必须是以换行结尾的一条 JSON 记录。
+// 返回的切片在下一次调用前有效，写入成功后需要调用 commit
+func (c *hashChain) link(p []byte) []byte {
+	prev := c.prev
+	if prev == nil {
+		prev = genesisHash
+	}
+	c.line = append(c.line[:0], `{"`+PrevHashKey+`":"`...)
+	c.line = append(c.line, prev...)
+	c.line = append(c.line, '"')
+	if body := bytes.TrimPrefix(p, []byte("{")); len(body) > 0 && body[0] != '}' {
+		c.line = append(c.line, ',')
+	}
+	return append(c.line, p[1:]...)
+}
+
+// commit 把 line（link 的返回值）记为上一条记录
+func (c *hashChain) commit(line []byte) {
+	c.prev = hashLine(line)
+}
+
+// resume 从已有日志文件的最后一条记录继续哈希链，文件为空时从头开始
+func (c *hashChain) resume(path string) error {
+	last, err := lastLine(path)
+	if err != nil {
+		return fmt.Errorf("failed to resume hash chain: %w", err)
+	}
+	c.prev = nil
+	if len(last) > 0 {
+		c.prev = hashLine(last)
+	}
+	return nil
+}
+
+func hashLine(line []byte) []byte {
+	sum := sha256.Sum256(bytes.TrimSuffix(line, []byte("\n")))
+	return []byte(hex.EncodeToString(sum[:]))
+}
+
+// lastLine 从文件末尾向前读，返回最后一个非空行
+func lastLine(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	const chunk = 64 << 10
+	var tail []byte
+	for pos := end; pos > 0; {
+		n := int64(chunk)
+		if pos < n {
+			n = pos
+		}
+		pos -= n
+		buf := make([]byte, n, int(n)+len(tail))
+		if _, err := f.ReadAt(buf, pos); err != nil {
+			return nil, err
+		}
+		tail = append(buf, tail...)
+		trimmed := bytes.TrimRight(tail, "\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+	}
+	return bytes.TrimRight(tail, "\n"), nil
+}
+
+// VerifyChain 校验用 LogConfig.FileHashChain 写出的日志文件：
+// 每条记录的 prev_hash 必须等于上一条记录的哈希，第一条记录必须是链的起点。
+// 校验失败时返回的错误包装 ErrChainBroken，并指出第一条出问题的行号
+func VerifyChain(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	prev := genesisHash
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var rec struct {
+				PrevHash *string `json:"prev_hash"`
+			}
+			if jsonErr := json.Unmarshal(line, &rec); jsonErr != nil {
+				return fmt.Errorf("%s:%d: invalid record: %w", path, n, ErrChainBroken)
+			}
+			if rec.PrevHash == nil {
+				return fmt.Errorf("%s:%d: missing %s: %w", path, n, PrevHashKey, ErrChainBroken)
+			}
+			if *rec.PrevHash != string(prev) {
+				return fmt.Errorf("%s:%d: %s does not match previous record: %w", path, n, PrevHashKey, ErrChainBroken)
+			}
+			prev = hashLine(line)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package xslog
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHashChainBatchedAsync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.log")
+	ml, err := NewLogger(LogConfig{
+		LogToFile:     true,
+		LogFilePath:   path,
+		IgnoreEnv:     true,
+		FileHashChain: true,
+		Async:         AsyncConfig{Enabled: true, BatchSize: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		ml.Info("record", "i", i)
+	}
+	if err := ml.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyChain(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashChainRequiresJSONFormat(t *testing.T) {
+	_, err := NewLogger(LogConfig{
+		LogToFile:     true,
+		LogFilePath:   filepath.Join(t.TempDir(), "a.log"),
+		IgnoreEnv:     true,
+		FileHashChain: true,
+		FileFormat:    CEFFormat{},
+	})
+	if err == nil {
+		t.Fatal("NewLogger accepted FileHashChain with CEFFormat")
+	}
+}
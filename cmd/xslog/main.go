@@ -0,0 +1,273 @@
+// xslog 把 xslog 写出的 JSON 日志文件按控制台格式显示，可以持续跟踪文件的新内容。
+//
+//	xslog [flags] [file ...] [key=value ...]
+//
+// 没有指定文件时读取标准输入，以 .gz 结尾的文件自动解压；key=value 参数只显示该属性等于 value 的记录，
+// key 可以用 "." 访问分组内的属性，如 req.method=GET。不是 JSON 的行原样输出。
+//
+//	xslog -f -level warn logs/app.log
+//	xslog -since 1h -n 50 logs/app.log logger=db
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xbfding/xslog"
+)
+
+// 跟踪文件时检查新内容的间隔
+const pollInterval = 250 * time.Millisecond
+
+type filter struct {
+	level   slog.Level
+	since   time.Time
+	matches [][2]string // key=value 条件
+}
+
+func (f *filter) match(r slog.Record) bool {
+	if r.Level < f.level || !f.since.IsZero() && r.Time.Before(f.since) {
+		return false
+	}
+	if len(f.matches) == 0 {
+		return true
+	}
+	rec := xslog.ObservedRecord{Time: r.Time, Level: r.Level, Message: r.Message}
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attrs = append(rec.Attrs, a)
+		return true
+	})
+	for _, m := range f.matches {
+		v, ok := rec.Attr(m[0])
+		if !ok || v.String() != m[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// printer 按控制台格式输出满足过滤条件的记录，每行前加上记录的时间
+type printer struct {
+	out     *bufio.Writer
+	handler *xslog.TxtColoredHandler
+	color   bool
+	filter  *filter
+}
+
+func (p *printer) line(line []byte) {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	r, err := xslog.UnmarshalRecord(line)
+	if err != nil {
+		// 不是 xslog 的记录（如 panic 输出），没有过滤条件时原样显示
+		if p.filter.level <= xslog.LevelTrace && p.filter.since.IsZero() && len(p.filter.matches) == 0 {
+			p.out.Write(line)
+			p.out.WriteByte('\n')
+		}
+		return
+	}
+	if !p.filter.match(r) {
+		return
+	}
+	if p.color {
+		p.out.WriteString("\x1b[90m")
+	}
+	p.out.WriteString(r.Time.Local().Format("2006-01-02 15:04:05.000"))
+	if p.color {
+		p.out.WriteString("\x1b[0m")
+	}
+	p.out.WriteByte(' ')
+	_ = p.handler.Handle(context.Background(), r)
+}
+
+func main() {
+	fs := flag.NewFlagSet("xslog", flag.ExitOnError)
+	follow := fs.Bool("f", false, "follow: keep reading data appended to the files")
+	tail := fs.Int("n", 0, "only show the last `N` lines of each file (0 shows all)")
+	level := fs.String("level", "", "minimum `level` to show, such as debug or warn")
+	since := fs.String("since", "", "only show records newer than a `duration` (such as 1h) or an RFC 3339 time")
+	noColor := fs.Bool("no-color", false, "disable ANSI colors")
+	flatten := fs.Bool("flatten", false, "show grouped attributes as parent.child.key=value")
+	levelStyle := fs.String("level-style", "short", "level label `style`: short (INF), full (INFO) or letter (I)")
+	lineColor := fs.Bool("line-color", false, "tint the whole line by level, not just the level tag")
+	sortAttrs := fs.Bool("sort", false, "sort attributes by key")
+	pin := fs.String("pin", "", "comma-separated `keys` to always show first, such as request_id,trace_id")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: xslog [flags] [file ...] [key=value ...]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+
+	f := &filter{level: slog.Level(-1 << 31)}
+	if *level != "" {
+		l, err := xslog.ParseLevel(*level)
+		if err != nil {
+			fatal(err)
+		}
+		f.level = l
+	}
+	if *since != "" {
+		if d, err := time.ParseDuration(*since); err == nil {
+			f.since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, *since); err == nil {
+			f.since = t
+		} else {
+			fatal(fmt.Errorf("-since: expected a duration or RFC 3339 time, got %q", *since))
+		}
+	}
+	var files []string
+	for _, arg := range fs.Args() {
+		if k, v, ok := strings.Cut(arg, "="); ok && k != "" {
+			f.matches = append(f.matches, [2]string{k, v})
+			continue
+		}
+		files = append(files, arg)
+	}
+
+	style, err := xslog.ParseLevelStyle(*levelStyle)
+	if err != nil {
+		fatal(fmt.Errorf("-level-style: %w", err))
+	}
+	var pinned []string
+	if *pin != "" {
+		pinned = strings.Split(*pin, ",")
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	handler := xslog.NewTxtColoredHandler(out, nil).WithColor(!*noColor).WithFlattenGroups(*flatten).
+		WithLineColor(*lineColor).WithSortedAttrs(*sortAttrs).WithPinnedKeys(pinned...).
+		WithLevelLabels(style, nil)
+	p := &printer{
+		out:     out,
+		handler: handler,
+		color:   !*noColor,
+		filter:  f,
+	}
+	if len(files) == 0 {
+		if err := read(p, os.Stdin, *tail); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	if *follow && len(files) > 1 {
+		fatal(errors.New("-f: only one file can be followed"))
+	}
+	for _, name := range files {
+		if err := readFile(p, name, *tail, *follow); err != nil {
+			out.Flush()
+			fatal(err)
+		}
+	}
+}
+
+// read 输出 r 中的所有行，tail 大于 0 时只输出最后 tail 行
+func read(p *printer, r io.Reader, tail int) error {
+	br := bufio.NewReaderSize(r, 64<<10)
+	var last [][]byte
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if tail > 0 {
+				if len(last) == tail {
+					last = last[1:]
+				}
+				last = append(last, line)
+			} else {
+				p.line(line)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, line := range last {
+		p.line(line)
+	}
+	return nil
+}
+
+// readFile 输出文件内容，follow 为 true 时之后持续输出追加的行，文件被截断后从头读起
+func readFile(p *printer, name string, tail int, follow bool) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if strings.HasSuffix(name, ".gz") {
+		if follow {
+			return fmt.Errorf("%s: -f: cannot follow a gzip file", name)
+		}
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return read(p, zr, tail)
+	}
+	if !follow {
+		return read(p, file, tail)
+	}
+
+	br := bufio.NewReaderSize(file, 64<<10)
+	var last [][]byte
+	var partial []byte
+	var offset int64
+	initial := true
+	for {
+		line, err := br.ReadBytes('\n')
+		offset += int64(len(line))
+		if err == nil {
+			line = append(partial, line...)
+			partial = nil
+			if initial && tail > 0 {
+				if len(last) == tail {
+					last = last[1:]
+				}
+				last = append(last, line)
+			} else {
+				p.line(line)
+			}
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		// 不完整的行留到下次读到换行再输出
+		partial = append(partial, line...)
+		if initial {
+			for _, l := range last {
+				p.line(l)
+			}
+			last, initial = nil, false
+		}
+		p.out.Flush()
+		time.Sleep(pollInterval)
+		if fi, err := file.Stat(); err == nil && fi.Size() < offset {
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			br.Reset(file)
+			partial, offset = nil, 0
+		}
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "xslog:", err)
+	os.Exit(1)
+}
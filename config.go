@@ -0,0 +1,583 @@
+package xslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig 是从配置文件解析出的完整配置
+type fileConfig struct {
+	config LogConfig
+	levels map[string]slog.Level // SetLevelFor 规则
+	raw    map[string]any        // 解码后的原始内容，Reload 用来找出改动了哪些键
+}
+
+// NewLoggerFromFile 根据配置文件创建 logger，按扩展名识别格式：.json、.yaml/.yml、.toml。
+// 配置示例（YAML）：
+//
+//	console:
+//	  enabled: true
+//	  level: info
+//	  flatten_groups: true  # 分组显示为 parent.child.key=value
+//	  line_color: true      # 整行按级别着色
+//	  level_style: full     # 级别标签写法：short（INF，默认）、full（INFO）或 letter（I）
+//	  level_labels:         # 自定义个别级别的标签
+//	    warn: WARNING
+//	  sort_attrs: true      # 属性按键名排序
+//	  pinned_keys: [request_id, trace_id]
+//	file:
+//	  enabled: true
+//	  path: logs/app.log
+//	  level: debug
+//	  sync_interval: 1s   # 或 sync_every: 100
+//	  buffer_size: 65536  # 写缓冲，配合 flush_interval: 1s
+//	  rotation:           # 写到 100MB 时轮转，保留 7 个旧文件，见 RotationConfig
+//	    max_size: 104857600
+//	    max_backups: 7
+//	    max_age: 168h
+//	outputs:              # 日志文件之外的 JSON 输出，见 Output
+//	  - name: collector
+//	    type: http        # http、stdout 或 stderr
+//	    url: https://logs.example.com/ingest
+//	    headers:
+//	      Authorization: Bearer xxx
+//	    timeout: 5s
+//	    max_attempts: 5
+//	    level: warn
+//	    format: ecs
+//	    dead_letter: logs/collector.dead
+//	stack_trace_level: error
+//	redact: [password, "*token*", "*_secret"]
+//	sampling:             # 每秒同一消息前 100 条全部记录，之后每 100 条记录一条
+//	  first: 100
+//	  thereafter: 100
+//	audit:
+//	  path: logs/audit.log
+//	  rotation:           # 审计文件单独轮转，字段与 file.rotation 相同
+//	    max_size: 104857600
+//	    max_age: 8760h
+//	limits:
+//	  max_message_bytes: 4096
+//	  max_value_bytes: 16384
+//	  max_attrs: 64
+//	ring_buffer: 1000     # 内存中保留最近的记录，见 Logger.Recent
+//	attrs:                # 每条记录都带上的固定属性
+//	  env: prod
+//	  region: eu-west-1
+//	process_info: true    # 每条记录带上 host、pid、exe
+//	error_causes: true    # 把 Err 的错误链展开为 error_causes 数组
+//	sequence: true        # 每条记录带上递增的序号 seq
+//	monotonic_time: true  # 每条记录带上单调时钟计算的距启动时长 mono
+//	goroutine_id: true    # 每条记录带上 goroutine 编号，开销较大，只用于排查问题
+//	build_info: startup   # 启动时记录版本信息，records 表示每条记录都带上
+//	levels:
+//	  "db.*": debug
+func NewLoggerFromFile(path string) (*Logger, error) {
+	fc, err := loadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ml, err := NewLogger(fc.config)
+	if err != nil {
+		return nil, err
+	}
+	ml.levelRules.replace(fc.levels)
+	ml.configPath = path
+	ml.configRaw = fc.raw
+	return ml, nil
+}
+
+func loadConfigFile(path string) (fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileConfig{}, fmt.Errorf("failed to read log config: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fileConfig{}, fmt.Errorf("%s: unsupported log config format %q, expected .json, .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fileConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	fc, err := parseConfig(raw)
+	if err != nil {
+		return fileConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	fc.raw = raw
+	return fc, nil
+}
+
+// parseConfig 校验并转换解码后的配置，错误信息带上出错的键（如 "console.level"）
+func parseConfig(raw map[string]any) (fileConfig, error) {
+	fc := fileConfig{levels: map[string]slog.Level{}}
+	err := eachKey(raw, func(key string, value any) error {
+		var err error
+		switch key {
+		case "console":
+			err = eachSection(key, value, func(sub string, value any) error {
+				switch sub {
+				case "enabled":
+					return decodeBool(key+"."+sub, value, &fc.config.LogToConsole)
+				case "level":
+					return decodeLevel(key+"."+sub, value, &fc.config.LevelForConsole)
+				case "flatten_groups":
+					return decodeBool(key+"."+sub, value, &fc.config.ConsoleFlattenGroups)
+				case "level_style":
+					var s string
+					if err := decodeString(key+"."+sub, value, &s); err != nil {
+						return err
+					}
+					style, err := ParseLevelStyle(s)
+					if err != nil {
+						return fmt.Errorf("%s.%s: %w", key, sub, err)
+					}
+					fc.config.ConsoleLevelStyle = style
+					return nil
+				case "level_labels":
+					fc.config.ConsoleLevelLabels = map[slog.Level]string{}
+					return eachSection(key+"."+sub, value, func(name string, value any) error {
+						path := key + "." + sub + "." + name
+						var level slog.Level
+						if err := decodeLevel(path, name, &level); err != nil {
+							return err
+						}
+						var label string
+						if err := decodeString(path, value, &label); err != nil {
+							return err
+						}
+						fc.config.ConsoleLevelLabels[level] = label
+						return nil
+					})
+				case "line_color":
+					return decodeBool(key+"."+sub, value, &fc.config.ConsoleLineColor)
+				case "sort_attrs":
+					return decodeBool(key+"."+sub, value, &fc.config.ConsoleSortAttrs)
+				case "pinned_keys":
+					return decodeStrings(key+"."+sub, value, &fc.config.ConsolePinnedKeys)
+				}
+				return unknownKey(key + "." + sub)
+			})
+		case "file":
+			err = eachSection(key, value, func(sub string, value any) error {
+				switch sub {
+				case "enabled":
+					return decodeBool(key+"."+sub, value, &fc.config.LogToFile)
+				case "path":
+					return decodeString(key+"."+sub, value, &fc.config.LogFilePath)
+				case "level":
+					return decodeLevel(key+"."+sub, value, &fc.config.LevelForFile)
+				case "sync_every":
+					return decodeInt(key+"."+sub, value, &fc.config.FileSync.Every)
+				case "sync_interval":
+					return decodeDuration(key+"."+sub, value, &fc.config.FileSync.Interval)
+				case "buffer_size":
+					return decodeInt(key+"."+sub, value, &fc.config.FileBufferSize)
+				case "flush_interval":
+					return decodeDuration(key+"."+sub, value, &fc.config.FileFlushInterval)
+				case "hash_chain":
+					return decodeBool(key+"."+sub, value, &fc.config.FileHashChain)
+				case "format":
+					return decodeFormat(key+"."+sub, value, &fc.config.FileFormat)
+				case "rotation":
+					return decodeRotation(key+"."+sub, value, &fc.config.Rotation)
+				case "signing_key":
+					var path string
+					if err := decodeString(key+"."+sub, value, &path); err != nil {
+						return err
+					}
+					k, err := LoadSigningKey(path)
+					if err != nil {
+						return fmt.Errorf("%s.%s: %w", key, sub, err)
+					}
+					fc.config.FileSigningKey = k
+					return nil
+				}
+				return unknownKey(key + "." + sub)
+			})
+		case "outputs":
+			var list []any
+			if list, err = decodeList(key, value); err == nil {
+				for i, v := range list {
+					var o Output
+					if o, err = parseOutput(fmt.Sprintf("%s[%d]", key, i), v); err != nil {
+						break
+					}
+					fc.config.Outputs = append(fc.config.Outputs, o)
+				}
+			}
+		case "stack_trace_level":
+			var level slog.Level
+			if err = decodeLevel(key, value, &level); err == nil {
+				fc.config.StackTraceLevel = level
+			}
+		case "sampling":
+			cfg := &SamplingConfig{}
+			fc.config.Sampling = cfg
+			err = eachSection(key, value, func(sub string, value any) error {
+				switch sub {
+				case "tick":
+					return decodeDuration(key+"."+sub, value, &cfg.Tick)
+				case "first":
+					return decodeInt(key+"."+sub, value, &cfg.Default.First)
+				case "thereafter":
+					return decodeInt(key+"."+sub, value, &cfg.Default.Thereafter)
+				}
+				return unknownKey(key + "." + sub)
+			})
+		case "audit":
+			cfg := &AuditConfig{}
+			fc.config.Audit = cfg
+			err = eachSection(key, value, func(sub string, value any) error {
+				switch sub {
+				case "path":
+					return decodeString(key+"."+sub, value, &cfg.Path)
+				case "sync_every":
+					return decodeInt(key+"."+sub, value, &cfg.Sync.Every)
+				case "sync_interval":
+					return decodeDuration(key+"."+sub, value, &cfg.Sync.Interval)
+				case "hash_chain":
+					return decodeBool(key+"."+sub, value, &cfg.HashChain)
+				case "rotation":
+					return decodeRotation(key+"."+sub, value, &cfg.Rotation)
+				}
+				return unknownKey(key + "." + sub)
+			})
+			if err == nil && cfg.Path == "" {
+				err = fmt.Errorf("%s.path: required", key)
+			}
+		case "limits":
+			cfg := &SizeLimits{}
+			fc.config.Limits = cfg
+			err = eachSection(key, value, func(sub string, value any) error {
+				switch sub {
+				case "max_message_bytes":
+					return decodeInt(key+"."+sub, value, &cfg.MaxMessageBytes)
+				case "max_value_bytes":
+					return decodeInt(key+"."+sub, value, &cfg.MaxValueBytes)
+				case "max_attrs":
+					return decodeInt(key+"."+sub, value, &cfg.MaxAttrs)
+				}
+				return unknownKey(key + "." + sub)
+			})
+		case "build_info":
+			var s string
+			if err = decodeString(key, value, &s); err == nil {
+				switch s {
+				case "off", "":
+					fc.config.BuildInfo = BuildInfoOff
+				case "records":
+					fc.config.BuildInfo = BuildInfoRecords
+				case "startup":
+					fc.config.BuildInfo = BuildInfoStartup
+				default:
+					err = fmt.Errorf("%s: expected off, records or startup, got %q", key, s)
+				}
+			}
+		case "attrs":
+			fc.config.Attrs = map[string]any{}
+			err = eachSection(key, value, func(name string, value any) error {
+				fc.config.Attrs[name] = value
+				return nil
+			})
+		case "error_causes":
+			err = decodeBool(key, value, &fc.config.ErrorCauses)
+		case "sequence":
+			err = decodeBool(key, value, &fc.config.Sequence)
+		case "monotonic_time":
+			err = decodeBool(key, value, &fc.config.MonotonicTime)
+		case "goroutine_id":
+			err = decodeBool(key, value, &fc.config.GoroutineID)
+		case "process_info":
+			err = decodeBool(key, value, &fc.config.ProcessInfo)
+		case "ring_buffer":
+			err = decodeInt(key, value, &fc.config.RingBuffer)
+		case "redact":
+			err = decodeStrings(key, value, &fc.config.Redact)
+		case "ignore_env":
+			err = decodeBool(key, value, &fc.config.IgnoreEnv)
+		case "levels":
+			err = eachSection(key, value, func(pattern string, value any) error {
+				var level slog.Level
+				if err := decodeLevel(key+"."+pattern, value, &level); err != nil {
+					return err
+				}
+				fc.levels[pattern] = level
+				return nil
+			})
+		default:
+			err = unknownKey(key)
+		}
+		return err
+	})
+	if err == nil && fc.config.LogToFile && fc.config.LogFilePath == "" {
+		err = fmt.Errorf("file.path: required when file.enabled is true")
+	}
+	return fc, err
+}
+
+// parseOutput 解析 outputs 中的一项，key 为它在配置中的位置，如 "outputs[0]"
+func parseOutput(key string, value any) (Output, error) {
+	var (
+		o       Output
+		kind    string
+		hc      HTTPConfig
+		timeout time.Duration
+	)
+	err := eachSection(key, value, func(sub string, value any) error {
+		switch sub {
+		case "name":
+			return decodeString(key+"."+sub, value, &o.Name)
+		case "type":
+			return decodeString(key+"."+sub, value, &kind)
+		case "level":
+			var level slog.Level
+			if err := decodeLevel(key+"."+sub, value, &level); err != nil {
+				return err
+			}
+			o.Level = level
+			return nil
+		case "format":
+			return decodeFormat(key+"."+sub, value, &o.Format)
+		case "dead_letter":
+			return decodeString(key+"."+sub, value, &o.DeadLetter)
+		case "url":
+			return decodeString(key+"."+sub, value, &hc.URL)
+		case "headers":
+			hc.Header = http.Header{}
+			return eachSection(key+"."+sub, value, func(name string, value any) error {
+				var v string
+				if err := decodeString(key+"."+sub+"."+name, value, &v); err != nil {
+					return err
+				}
+				hc.Header.Set(name, v)
+				return nil
+			})
+		case "content_type":
+			return decodeString(key+"."+sub, value, &hc.ContentType)
+		case "timeout":
+			return decodeDuration(key+"."+sub, value, &timeout)
+		case "max_attempts":
+			return decodeInt(key+"."+sub, value, &hc.Retry.MaxAttempts)
+		}
+		return unknownKey(key + "." + sub)
+	})
+	if err != nil {
+		return Output{}, err
+	}
+	switch kind {
+	case "http":
+		if hc.URL == "" {
+			return Output{}, fmt.Errorf("%s.url: required for http outputs", key)
+		}
+		if timeout > 0 {
+			hc.Client = &http.Client{Timeout: timeout}
+		}
+		o.Writer = NewHTTPWriter(hc)
+	case "stdout":
+		o.Writer = os.Stdout
+	case "stderr":
+		o.Writer = os.Stderr
+	case "":
+		return Output{}, fmt.Errorf("%s.type: required", key)
+	default:
+		return Output{}, fmt.Errorf("%s.type: expected http, stdout or stderr, got %q", key, kind)
+	}
+	return o, nil
+}
+
+// eachKey 按键名排序遍历，保证多处错误时总是报告同一个
+func eachKey(m map[string]any, fn func(key string, value any) error) error {
+	for _, key := range sortedKeys(m) {
+		if err := fn(key, m[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eachSection(key string, value any, fn func(sub string, value any) error) error {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: expected a table, got %T", key, value)
+	}
+	return eachKey(m, fn)
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf("%s: unknown key", key)
+}
+
+func decodeBool(key string, value any, dst *bool) error {
+	b, ok := value.(bool)
+	if !ok {
+		return fmt.Errorf("%s: expected a boolean, got %T", key, value)
+	}
+	*dst = b
+	return nil
+}
+
+func decodeString(key string, value any, dst *string) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%s: expected a string, got %T", key, value)
+	}
+	*dst = s
+	return nil
+}
+
+// configFormats 是配置文件中可以使用的格式名称
+var configFormats = map[string]Format{
+	"json": nil,
+	"ecs":  FormatECS,
+	"gcp":  GCPFormat{},
+	"cef":  CEFFormat{},
+	"leef": LEEFFormat{},
+
+	"access_log":       AccessLogFormat{Style: AccessLogCombined},
+	"access_log_nginx": AccessLogFormat{Style: AccessLogNginx},
+}
+
+func decodeFormat(key string, value any, dst *Format) error {
+	var name string
+	if err := decodeString(key, value, &name); err != nil {
+		return err
+	}
+	f, err := lookupFormat(name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = f
+	return nil
+}
+
+// lookupFormat 按 configFormats 中的名称查找格式，不区分大小写，配置文件和环境变量共用
+func lookupFormat(name string) (Format, error) {
+	f, ok := configFormats[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", name)
+	}
+	return f, nil
+}
+
+func decodeInt(key string, value any, dst *int) error {
+	switch v := value.(type) {
+	case int:
+		*dst = v
+	case int64:
+		*dst = int(v)
+	case float64:
+		if v != float64(int(v)) {
+			return fmt.Errorf("%s: expected an integer, got %v", key, v)
+		}
+		*dst = int(v)
+	default:
+		return fmt.Errorf("%s: expected an integer, got %T", key, value)
+	}
+	return nil
+}
+
+// decodeDuration 接受 time.ParseDuration 格式的字符串，如 "500ms"、"1s"
+func decodeDuration(key string, value any, dst *time.Duration) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%s: expected a duration string such as \"1s\", got %T", key, value)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = d
+	return nil
+}
+
+// decodeRotation 解析 file.rotation 和 audit.rotation
+func decodeRotation(key string, value any, dst **RotationConfig) error {
+	cfg := &RotationConfig{}
+	*dst = cfg
+	return eachSection(key, value, func(sub string, value any) error {
+		switch sub {
+		case "max_size":
+			var n int
+			err := decodeInt(key+"."+sub, value, &n)
+			cfg.MaxSize = int64(n)
+			return err
+		case "max_backups":
+			return decodeInt(key+"."+sub, value, &cfg.MaxBackups)
+		case "max_age":
+			return decodeDuration(key+"."+sub, value, &cfg.MaxAge)
+		}
+		return unknownKey(key + "." + sub)
+	})
+}
+
+// decodeList 接受数组，TOML 的表数组（[[outputs]]）解码为 []map[string]any，同样接受
+func decodeList(key string, value any) ([]any, error) {
+	switch v := value.(type) {
+	case []any:
+		return v, nil
+	case []map[string]any:
+		list := make([]any, len(v))
+		for i, m := range v {
+			list[i] = m
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("%s: expected a list, got %T", key, value)
+}
+
+func decodeStrings(key string, value any, dst *[]string) error {
+	list, ok := value.([]any)
+	if !ok {
+		return fmt.Errorf("%s: expected a list of strings, got %T", key, value)
+	}
+	for i, v := range list {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s[%d]: expected a string, got %T", key, i, v)
+		}
+		*dst = append(*dst, s)
+	}
+	return nil
+}
+
+// decodeLevel 接受 ParseLevel 支持的字符串，或直接写数字
+func decodeLevel(key string, value any, dst *slog.Level) error {
+	switch v := value.(type) {
+	case string:
+		level, err := ParseLevel(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		*dst = level
+	case int:
+		*dst = slog.Level(v)
+	case int64:
+		*dst = slog.Level(v)
+	case float64:
+		if v != float64(int(v)) {
+			return fmt.Errorf("%s: level must be an integer, got %v", key, v)
+		}
+		*dst = slog.Level(v)
+	default:
+		return fmt.Errorf("%s: expected a level name or number, got %T", key, value)
+	}
+	return nil
+}
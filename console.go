@@ -0,0 +1,493 @@
+package xslog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// 控制台输出时间类型属性的格式
+const consoleTimeFormat = "2006-01-02 15:04:05.000"
+
+type TxtColoredHandler struct {
+	out     io.Writer
+	opts    *slog.HandlerOptions
+	mu      *sync.Mutex
+	attrs   []slog.Attr // WithAttrs 附加的属性
+	groups  []string    // WithGroup 打开的分组
+	pii     PIIPolicy   // 如何显示 PII 标记的属性
+	noColor bool        // 为 true 时级别标签不带颜色
+	flatten bool        // 为 true 时分组显示为 parent.child.key=value，而不是 parent=[child=[key=value]]
+
+	lineColor bool     // 为 true 时整行使用级别的颜色，而不只是级别标签
+	sortAttrs bool     // 为 true 时顶层属性按键名排序，否则按添加顺序
+	pinned    []string // 总是排在最前面的顶层属性键名，按给出的顺序
+
+	levelStyle  LevelStyle            // 级别标签的写法
+	levelLabels map[slog.Level]string // 代替 levelStyle 的级别标签，如本地化的名称
+	theme       Theme                 // 配色，零值为默认配色
+}
+
+func NewTxtColoredHandler(out io.Writer, opts *slog.HandlerOptions) *TxtColoredHandler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	return &TxtColoredHandler{
+		opts: opts,
+		out:  out,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (h *TxtColoredHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	// 如果没有设置 Level，则默认启用所有级别
+	if h.opts.Level == nil {
+		return true
+	}
+	// 检查当前级别是否符合配置的级别
+	return level >= h.opts.Level.Level()
+}
+
+func (h *TxtColoredHandler) Handle(ctx context.Context, r slog.Record) error {
+	line, blocks := getBuffer(), getBuffer()
+	defer putBuffer(line)
+	defer putBuffer(blocks)
+
+	lineColor := h.lineColor && !h.noColor
+	switch {
+	case h.noColor:
+		*line = append(*line, '[')
+		*line = append(*line, h.levelLabel(r.Level)...)
+		*line = append(*line, "] "...)
+	case lineColor:
+		// 整行使用级别的颜色，行内不再单独着色
+		*line = append(*line, "\x1b["...)
+		*line = strconv.AppendInt(*line, int64(h.theme.levelColor(r.Level)), 10)
+		*line = append(*line, "m["...)
+		*line = append(*line, h.levelLabel(r.Level)...)
+		*line = append(*line, "] "...)
+	default:
+		*line = append(*line, "[\x1b["...)
+		*line = strconv.AppendInt(*line, int64(h.theme.levelColor(r.Level)), 10)
+		*line = append(*line, 'm')
+		*line = append(*line, h.levelLabel(r.Level)...)
+		*line = append(*line, "\x1b[0m] "...)
+	}
+	*line = appendEscaped(*line, r.Message)
+
+	switch {
+	case h.sortAttrs || len(h.pinned) > 0:
+		attrs := append(make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs()), h.attrs...)
+		attrs = append(attrs, h.nestInGroups(h.recordAttrs(r))...)
+		if len(h.groups) > 0 {
+			attrs = mergeGroups(attrs)
+		}
+		for _, a := range h.orderAttrs(attrs) {
+			h.appendAttr(line, blocks, a)
+		}
+	case len(h.groups) == 0:
+		for _, a := range h.attrs {
+			h.appendAttr(line, blocks, a)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			a, _ = piiAttr(a, h.pii)
+			h.appendAttr(line, blocks, a)
+			return true
+		})
+	default:
+		attrs := append(make([]slog.Attr, 0, len(h.attrs)+1), h.attrs...)
+		attrs = append(attrs, h.nestInGroups(h.recordAttrs(r))...)
+		for _, a := range mergeGroups(attrs) {
+			h.appendAttr(line, blocks, a)
+		}
+	}
+	// 多行内容（如堆栈）缩进后放在日志行之后
+	*line = append(*line, *blocks...)
+	if lineColor {
+		*line = append(*line, "\x1b[0m"...)
+	}
+	*line = append(*line, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out.Write(*line)
+	return err
+}
+
+func (h *TxtColoredHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	own := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		own[i], _ = piiAttr(a, h.pii)
+	}
+	h2.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], h.nestInGroups(own)...)
+	return &h2
+}
+
+func (h *TxtColoredHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// WithColor 返回启用或关闭 ANSI 颜色的 handler，NewTxtColoredHandler 默认启用：
+// 级别标签按级别着色，所有属性的键名暗淡显示，错误（Err 以及键名为 error、err 的属性）的值显示为红色
+func (h *TxtColoredHandler) WithColor(enabled bool) *TxtColoredHandler {
+	h2 := *h
+	h2.noColor = !enabled
+	return &h2
+}
+
+// WithTheme 返回使用配色 t 的 handler，t 中为 0 的颜色保持默认；关闭颜色时不起作用
+func (h *TxtColoredHandler) WithTheme(t Theme) *TxtColoredHandler {
+	h2 := *h
+	h2.theme = t
+	return &h2
+}
+
+// WithLineColor 返回整行按级别着色的 handler：enabled 为 true 时日志行（包括之后的堆栈等多行内容）
+// 都使用级别标签的颜色，键名和错误不再单独着色，便于在滚动的输出中找到警告；默认只有级别标签着色
+func (h *TxtColoredHandler) WithLineColor(enabled bool) *TxtColoredHandler {
+	h2 := *h
+	h2.lineColor = enabled
+	return &h2
+}
+
+// WithLevelLabels 返回自定义级别标签的 handler：style 决定标签的写法（缩写 "INF"、完整名称 "INFO"
+// 或单个字母 "I"），labels 中的级别改用给出的标签，如本地化的名称或下游工具要求的写法
+//
+//	h.WithLevelLabels(xslog.LevelStyleFull, map[slog.Level]string{slog.LevelWarn: "WARNING"})
+func (h *TxtColoredHandler) WithLevelLabels(style LevelStyle, labels map[slog.Level]string) *TxtColoredHandler {
+	h2 := *h
+	h2.levelStyle = style
+	h2.levelLabels = labels
+	return &h2
+}
+
+// levelLabel 返回控制台中 level 的标签
+func (h *TxtColoredHandler) levelLabel(level slog.Level) string {
+	if label, ok := h.levelLabels[level]; ok {
+		return label
+	}
+	return h.levelStyle.label(level)
+}
+
+// WithFlattenGroups 返回把分组展开显示的 handler：enabled 为 true 时分组中的每个属性
+// 单独显示为 parent.child.key=value，便于 grep；默认显示为 parent=[child=[key=value]]
+func (h *TxtColoredHandler) WithFlattenGroups(enabled bool) *TxtColoredHandler {
+	h2 := *h
+	h2.flatten = enabled
+	return &h2
+}
+
+// WithSortedAttrs 返回按键名排序顶层属性的 handler，enabled 为 false 时按添加顺序显示（默认）；
+// Err 的错误按 error 排序。排序作用于 With 和记录的属性整体，分组内部保持原来的顺序
+func (h *TxtColoredHandler) WithSortedAttrs(enabled bool) *TxtColoredHandler {
+	h2 := *h
+	h2.sortAttrs = enabled
+	return &h2
+}
+
+// WithPinnedKeys 返回把 keys 对应的顶层属性按给出的顺序放在每行最前面的 handler，
+// 如 request_id、trace_id，便于逐行对照；其余属性按 WithSortedAttrs 的设置排列
+func (h *TxtColoredHandler) WithPinnedKeys(keys ...string) *TxtColoredHandler {
+	h2 := *h
+	h2.pinned = append([]string(nil), keys...)
+	return &h2
+}
+
+// mergeGroups 合并相邻的同名分组，WithGroup 之后 WithAttrs 和记录的属性分别包在同名分组中，
+// 合并后显示为一个 req=[id=7 path=/x]，而不是 req=[id=7] req=[path=/x]
+func mergeGroups(attrs []slog.Attr) []slog.Attr {
+	var out []slog.Attr
+	for _, a := range attrs {
+		if n := len(out); n > 0 && a.Key != "" && out[n-1].Key == a.Key &&
+			a.Value.Kind() == slog.KindGroup && out[n-1].Value.Kind() == slog.KindGroup {
+			merged := append(append([]slog.Attr(nil), out[n-1].Value.Group()...), a.Value.Group()...)
+			out[n-1] = slog.Attr{Key: a.Key, Value: slog.GroupValue(mergeGroups(merged)...)}
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// recordAttrs 返回按 PII 策略处理过的记录属性
+func (h *TxtColoredHandler) recordAttrs(r slog.Record) []slog.Attr {
+	if r.NumAttrs() == 0 {
+		return nil
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		a, _ = piiAttr(a, h.pii)
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+// orderAttrs 按 pinned 和 sortAttrs 重新排列顶层属性，空 key 的分组先展开到顶层参与排序
+func (h *TxtColoredHandler) orderAttrs(attrs []slog.Attr) []slog.Attr {
+	flat := attrs[:0:0]
+	var expand func(attrs []slog.Attr)
+	expand = func(attrs []slog.Attr) {
+		for _, a := range attrs {
+			if a.Key == "" && a.Value.Kind() == slog.KindGroup {
+				expand(a.Value.Group())
+				continue
+			}
+			flat = append(flat, a)
+		}
+	}
+	expand(attrs)
+	rank := func(key string) int {
+		for i, p := range h.pinned {
+			if p == key {
+				return i
+			}
+		}
+		return len(h.pinned)
+	}
+	sort.SliceStable(flat, func(i, j int) bool {
+		ki, kj := consoleSortKey(flat[i]), consoleSortKey(flat[j])
+		if ri, rj := rank(ki), rank(kj); ri != rj {
+			return ri < rj
+		}
+		return h.sortAttrs && ki < kj
+	})
+	return flat
+}
+
+// consoleSortKey 返回排序用的键名，Err 的空 key 属性按 error 排序
+func consoleSortKey(a slog.Attr) string {
+	if a.Key == "" && a.Value.Kind() == slog.KindLogValuer {
+		if _, ok := a.Value.Any().(errorValue); ok {
+			return ErrorKey
+		}
+	}
+	return a.Key
+}
+
+// nestInGroups 把属性包进当前打开的分组，没有属性时分组也不输出
+func (h *TxtColoredHandler) nestInGroups(attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: h.groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+// appendAttr 把属性按 logfmt 的习惯格式化为 " key=value" 追加到 line，分组显示为 key=[...]
+// 或展开为 parent.child.key=value；堆栈、错误链等多行内容追加到 blocks
+func (h *TxtColoredHandler) appendAttr(line, blocks *[]byte, a slog.Attr) {
+	// 只有 LogValuer 才可能是堆栈或错误，其他类型调用 Any 会额外分配内存
+	var v any
+	if a.Value.Kind() == slog.KindLogValuer {
+		v = a.Value.Any()
+	}
+	if stack, ok := v.(stackValue); ok {
+		*blocks = stack.appendBlock(*blocks)
+		return
+	}
+	if ev, ok := v.(errorValue); ok {
+		for _, cause := range ev.causes {
+			*blocks = append(*blocks, "\n    caused by: "...)
+			*blocks = appendEscaped(*blocks, cause.Message)
+			*blocks = append(*blocks, " ("...)
+			*blocks = append(*blocks, cause.Type...)
+			*blocks = append(*blocks, ')')
+		}
+		if len(ev.stack) > 0 {
+			*blocks = ev.stack.appendBlock(*blocks)
+		}
+	} else if a.Key == "" && a.Value.Kind() == slog.KindGroup {
+		// 空 key 的分组按 slog 的约定展开到当前层级
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(line, blocks, ga)
+		}
+		return
+	}
+	*line, _ = h.appendPart(*line, "", a, false)
+}
+
+// 默认配色：键名暗淡显示，错误显示为红色
+const (
+	colorKey   = 2
+	colorError = 31
+)
+
+// Theme 是控制台的配色，值为 ANSI SGR 代码（如 31 红色、1 粗体、93 亮黄色），为 0 时使用默认配色
+//
+//	xslog.Theme{Key: 36, Levels: map[slog.Level]int{slog.LevelWarn: 93}}
+type Theme struct {
+	Key    int                // 属性键名，默认 2（暗淡）
+	Error  int                // 错误属性的值，默认 31（红色）
+	Levels map[slog.Level]int // 级别标签（整行着色时为整行）的颜色，默认为 LevelSpec.Color
+}
+
+func (t Theme) keyColor() int {
+	if t.Key != 0 {
+		return t.Key
+	}
+	return colorKey
+}
+
+func (t Theme) errorColor() int {
+	if t.Error != 0 {
+		return t.Error
+	}
+	return colorError
+}
+
+func (t Theme) levelColor(level slog.Level) int {
+	if c := t.Levels[level]; c != 0 {
+		return c
+	}
+	return getLevelColor(level)
+}
+
+// startColor 在启用颜色且不是整行着色时追加设置颜色的 ANSI 序列
+func (h *TxtColoredHandler) startColor(buf []byte, code int) []byte {
+	if h.noColor || h.lineColor {
+		return buf
+	}
+	buf = append(buf, "\x1b["...)
+	buf = strconv.AppendInt(buf, int64(code), 10)
+	return append(buf, 'm')
+}
+
+// endColor 在启用颜色时追加恢复默认颜色的 ANSI 序列
+func (h *TxtColoredHandler) endColor(buf []byte) []byte {
+	if h.noColor || h.lineColor {
+		return buf
+	}
+	return append(buf, "\x1b[0m"...)
+}
+
+// appendKey 追加属性的 "prefix+key="，顶层属性和分组内的属性都经过这里，启用颜色时暗淡显示
+func (h *TxtColoredHandler) appendKey(buf []byte, prefix, key string) []byte {
+	buf = h.startColor(buf, h.theme.keyColor())
+	buf = append(buf, prefix...)
+	buf = appendEscaped(buf, key)
+	buf = append(buf, '=')
+	return h.endColor(buf)
+}
+
+// isErrorKey 报告 key 是否是错误属性的键名，这些属性的值显示为红色
+func isErrorKey(key string) bool {
+	return key == ErrorKey || key == "err"
+}
+
+// appendValue 按值的类型直接追加到 buf，只有未知类型才交给 fmt；文本中的控制字符会被转义，需要时加上引号
+func (h *TxtColoredHandler) appendValue(buf []byte, v slog.Value) []byte {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return appendQuoted(buf, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return strconv.AppendFloat(buf, v.Float64(), 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		return append(buf, v.Duration().String()...)
+	case slog.KindTime:
+		// 时间中有空格，与字符串一样加引号
+		buf = append(buf, '"')
+		buf = v.Time().AppendFormat(buf, consoleTimeFormat)
+		return append(buf, '"')
+	case slog.KindGroup:
+		buf = append(buf, '[')
+		buf, _ = h.appendGroupParts(buf, "", v.Group(), true)
+		return append(buf, ']')
+	}
+	switch x := v.Any().(type) {
+	case error:
+		return appendQuoted(buf, x.Error())
+	case fmt.Stringer:
+		return appendQuoted(buf, x.String())
+	case []byte:
+		return appendQuoted(buf, string(x))
+	}
+	return appendQuoted(buf, fmt.Sprint(v.Any()))
+}
+
+// appendGroupParts 把分组内的属性格式化为以空格分隔的 key=value，空 key 的分组展开到当前层级；
+// prefix 是展开分组（flatten）时加在每个键前面的父分组路径，如 "req."。
+// first 表示 buf 中还没有写入分组内的属性，返回值含义相同
+func (h *TxtColoredHandler) appendGroupParts(buf []byte, prefix string, attrs []slog.Attr, first bool) ([]byte, bool) {
+	for _, a := range attrs {
+		buf, first = h.appendPart(buf, prefix, a, first)
+	}
+	return buf, first
+}
+
+// appendPart 把一个属性格式化为 key=value，first 为 false 时先写一个空格；
+// 属性为空或是空分组时不写任何内容，first 原样返回
+func (h *TxtColoredHandler) appendPart(buf []byte, prefix string, a slog.Attr, first bool) ([]byte, bool) {
+	if a.Value.Kind() == slog.KindLogValuer {
+		if ev, ok := a.Value.Any().(errorValue); ok {
+			if !first {
+				buf = append(buf, ' ')
+			}
+			buf = h.appendKey(buf, prefix, ErrorKey)
+			buf = h.startColor(buf, h.theme.errorColor())
+			buf = appendQuoted(buf, ev.err.Error())
+			buf = h.endColor(buf)
+			buf = append(buf, ' ')
+			buf = h.appendKey(buf, prefix, ErrorTypeKey)
+			return append(buf, ev.errType()...), false
+		}
+		if isHumanValue(a.Value) {
+			if !first {
+				buf = append(buf, ' ')
+			}
+			buf = h.appendKey(buf, prefix, a.Key)
+			return appendHumanValue(buf, a.Value), false
+		}
+	}
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) || a.Value.Kind() == slog.KindGroup && len(a.Value.Group()) == 0 {
+		return buf, first
+	}
+	if a.Key == "" && a.Value.Kind() == slog.KindGroup {
+		return h.appendGroupParts(buf, prefix, a.Value.Group(), first)
+	}
+	if h.flatten && a.Value.Kind() == slog.KindGroup {
+		return h.appendGroupParts(buf, prefix+a.Key+".", a.Value.Group(), first)
+	}
+	if !first {
+		buf = append(buf, ' ')
+	}
+	buf = h.appendKey(buf, prefix, a.Key)
+	if isErrorKey(a.Key) && a.Value.Kind() != slog.KindGroup {
+		buf = h.startColor(buf, h.theme.errorColor())
+		buf = h.appendValue(buf, a.Value)
+		return h.endColor(buf), false
+	}
+	return h.appendValue(buf, a.Value), false
+}
+
+// appendBlock 把堆栈格式化为缩进的多行文本，每行之前换行
+func (s stackValue) appendBlock(buf []byte) []byte {
+	for _, frame := range s {
+		buf = append(buf, "\n    "...)
+		buf = append(buf, frame...)
+	}
+	return buf
+}
@@ -0,0 +1,44 @@
+package xslog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func BenchmarkTxtColoredHandler(b *testing.B) {
+	ctx := context.Background()
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "request done", 0)
+	r.AddAttrs(
+		slog.String("method", "GET"),
+		slog.String("path", "/api/users"),
+		slog.Int("status", 200),
+		slog.Duration("took", 1500*time.Microsecond),
+	)
+	b.Run("attrs", func(b *testing.B) {
+		h := NewTxtColoredHandler(io.Discard, nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = h.Handle(ctx, r)
+		}
+	})
+	b.Run("group", func(b *testing.B) {
+		h := NewTxtColoredHandler(io.Discard, nil).WithGroup("req").WithAttrs([]slog.Attr{slog.Int("id", 7)})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = h.Handle(ctx, r)
+		}
+	})
+	b.Run("error", func(b *testing.B) {
+		h := NewTxtColoredHandler(io.Discard, nil)
+		r := r.Clone()
+		r.AddAttrs(Err(errors.New("connection reset by peer")))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = h.Handle(ctx, r)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package xslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ContextExtractor 从 context 中提取请求级别的属性（如 request_id、user_id）
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+// AddContextExtractor 注册 context 属性提取器，
+// 之后通过 XxxContext 方法记录的日志都会自动附加提取到的属性。
+// 提取器注册在整个 logger 树上：父 logger、With 和 Named 派生的子 logger 共用同一组提取器，
+// 在任何一个上注册都对所有 logger 生效。可以在记录日志的同时调用
+func (ml *Logger) AddContextExtractor(fns ...ContextExtractor) {
+	for {
+		old := ml.extractors.Load()
+		var next []ContextExtractor
+		if old != nil {
+			// 复制一份再追加，正在执行的 contextAttrs 不受影响
+			next = append(next, *old...)
+		}
+		for _, fn := range fns {
+			if fn != nil {
+				next = append(next, fn)
+			}
+		}
+		if ml.extractors.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// contextAttrs 依次执行提取器，返回提取到的属性
+func (ml *Logger) contextAttrs(ctx context.Context) []slog.Attr {
+	extractors := ml.extractors.Load()
+	if extractors == nil || len(*extractors) == 0 {
+		return nil
+	}
+	var attrs []slog.Attr
+	for _, fn := range *extractors {
+		attrs = append(attrs, fn(ctx)...)
+	}
+	return attrs
+}
+
+type loggerContextKey struct{}
+
+// NewContext 返回携带 logger 的新 context
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext 取出 NewContext 存入的 logger，
+// 没有时返回默认 logger，调用方无需判空
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return Default()
+}
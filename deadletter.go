@@ -0,0 +1,110 @@
+package xslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// 重放死信时每次写出的最大字节数，按整条记录切分
+const deadLetterChunk = 256 << 10
+
+// deadLetterWriter 在 w 写入失败（重试用完或熔断）时把内容追加到本地死信文件，
+// 之后由 Logger.ReplayDeadLetters 重新发送。w 的错误仍然返回，由 OnError 报告
+type deadLetterWriter struct {
+	name string
+	w    io.Writer
+	path string
+	mu   sync.Mutex // 保护死信文件
+}
+
+func (d *deadLetterWriter) Write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	if err == nil {
+		return n, nil
+	}
+	if dlErr := d.append(p); dlErr != nil {
+		return n, errors.Join(err, dlErr)
+	}
+	return n, err
+}
+
+func (d *deadLetterWriter) append(p []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	f, err := openLogFile(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file: %w", err)
+	}
+	_, err = f.Write(p)
+	return errors.Join(err, f.Close())
+}
+
+// replay 把死信文件中的记录按顺序写给 w，全部成功后删除文件；
+// 中途失败时保留尚未发送的部分
+func (d *deadLetterWriter) replay(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	data, err := os.ReadFile(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sent, err := writeChunks(d.w, data, ctx.Done())
+	if err == nil && sent < len(data) {
+		err = ctx.Err()
+	}
+	if err == nil {
+		return os.Remove(d.path)
+	}
+	if sent > 0 {
+		if werr := os.WriteFile(d.path, data[sent:], 0666); werr != nil {
+			return errors.Join(err, werr)
+		}
+	}
+	return err
+}
+
+// writeChunks 把 data 按整条记录切分成不超过 deadLetterChunk 的块依次写给 w，
+// 出错或 done 关闭时停止，返回已经写出的字节数
+func writeChunks(w io.Writer, data []byte, done <-chan struct{}) (int, error) {
+	sent := 0
+	for sent < len(data) {
+		select {
+		case <-done:
+			return sent, nil
+		default:
+		}
+		chunk := data[sent:]
+		if len(chunk) > deadLetterChunk {
+			if i := bytes.LastIndexByte(chunk[:deadLetterChunk], '\n'); i >= 0 {
+				chunk = chunk[:i+1]
+			}
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return sent, err
+		}
+		sent += len(chunk)
+	}
+	return sent, nil
+}
+
+// ReplayDeadLetters 把各个 Output 死信文件中的记录重新发送，
+// 通常在确认远程服务恢复后或程序启动时调用。一个输出失败时仍会重放其他输出，
+// 返回的错误用 errors.Join 合并所有失败输出的错误，每个都带上输出名称
+func (ml *Logger) ReplayDeadLetters(ctx context.Context) error {
+	var errs []error
+	for _, d := range ml.deadLetters {
+		if err := d.replay(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("replay dead letters of %s: %w", d.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
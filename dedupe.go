@@ -0,0 +1,124 @@
+package xslog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RepeatedKey 是重复记录汇总行上的属性名，值为被合并的重复次数
+const RepeatedKey = "repeated"
+
+// deduper 像 syslog 一样合并连续的相同记录：第一条照常输出，
+// 从第一条开始的 Window 内的重复记录只计数，遇到不同的记录或 Window 结束时输出一条带 repeated 属性的汇总
+type deduper struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	key     string
+	handler *MultiHandler // 上一条记录所属 logger 的输出
+	ctx     context.Context
+	start   time.Time   // 这一组记录中第一条的时间，Window 从这里算起
+	last    slog.Record // 上一条记录
+	force   bool
+	count   int // 被合并的重复次数
+	timer   *time.Timer
+	gen     uint64 // 每开始一组记录加一，过期的定时器据此放弃
+}
+
+func newDeduper(window time.Duration) *deduper {
+	if window <= 0 {
+		return nil
+	}
+	return &deduper{window: window}
+}
+
+// handle 输出或合并 r，targets 是 r 要写入的输出
+func (d *deduper) handle(ctx context.Context, h *MultiHandler, r slog.Record, targets []slog.Handler, force bool) error {
+	key := dedupeKey(r)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// 同一条消息来自 With 派生出的不同 logger 时不合并
+	if elapsed := r.Time.Sub(d.start); h == d.handler && key == d.key && elapsed < d.window {
+		d.count++
+		// 汇总行带上最后一条重复记录的时间和序号
+		d.last = r.Clone()
+		if d.count == 1 {
+			gen := d.gen
+			d.timer = time.AfterFunc(d.window-elapsed, func() { d.expire(gen) })
+		}
+		return nil
+	}
+	d.flushLocked()
+	d.gen++
+	d.key, d.handler, d.ctx, d.start, d.last, d.force = key, h, ctx, r.Time, r.Clone(), force
+	return dispatch(ctx, r, targets, force)
+}
+
+// expire 在 Window 结束时输出尚未报告的重复次数；gen 不同说明定时器触发后、拿到锁之前
+// 已经开始了新的一组记录，这组记录由自己的定时器处理
+func (d *deduper) expire(gen uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if gen != d.gen {
+		return
+	}
+	d.flushLocked()
+	d.handler = nil
+}
+
+// flushLocked 输出汇总行，调用方持有 d.mu
+func (d *deduper) flushLocked() {
+	if d.count == 0 {
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	r := d.last.Clone()
+	r.AddAttrs(slog.Any(RepeatedKey, repeatCount(d.count)))
+	d.count = 0
+	var buf [4]slog.Handler
+	_ = dispatch(d.ctx, r, d.handler.targets(d.ctx, r.Level, d.force, buf[:0]), d.force)
+}
+
+// flush 输出尚未报告的重复次数
+func (d *deduper) flush() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.flushLocked()
+	d.handler = nil
+}
+
+// dedupeKey 由级别、消息和属性组成，属性相同但顺序不同视为不同的记录；
+// 每条记录都不同的序号（seq）、单调时间（mono）和 goroutine 编号不算在内
+func dedupeKey(r slog.Record) string {
+	var b strings.Builder
+	b.WriteString(r.Level.String())
+	b.WriteByte(0)
+	b.WriteString(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case SeqKey, MonotonicKey, GoroutineKey:
+			return true
+		}
+		b.WriteByte(0)
+		b.WriteString(a.String())
+		return true
+	})
+	return b.String()
+}
+
+// repeatCount 是 Dedupe 汇总行的重复次数，JSON 和控制台中都输出为数字（repeated=N）
+type repeatCount int
+
+func (n repeatCount) LogValue() slog.Value {
+	return slog.IntValue(int(n))
+}
@@ -0,0 +1,39 @@
+package xslog
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestDedupeWindowFromFirstRecord(t *testing.T) {
+	obs := NewObserverSink(slog.LevelDebug)
+	now := time.Unix(0, 0)
+	ml, err := NewLogger(LogConfig{
+		IgnoreEnv: true,
+		Handlers:  []slog.Handler{obs},
+		Dedupe:    time.Hour,
+		Clock:     func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ml.Close()
+
+	// 重复记录不顺延窗口：第一条之后一小时的记录开始新的一组
+	for i := 0; i < 4; i++ {
+		ml.Info("tick")
+		now = now.Add(20 * time.Minute)
+	}
+	ml.Flush()
+	rs := obs.Records()
+	if rs.Len() != 3 {
+		t.Fatalf("got %d records, want 3", rs.Len())
+	}
+	if v, _ := rs[1].Attr(RepeatedKey); v.Int64() != 2 {
+		t.Errorf("summary: repeated = %v, want 2", v)
+	}
+	if _, ok := rs[2].Attr(RepeatedKey); ok {
+		t.Error("record after the window was merged into the first run")
+	}
+}
@@ -0,0 +1,51 @@
+package xslog
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+var defaultLogger atomic.Pointer[Logger]
+
+func init() {
+	// 默认只输出到控制台，级别为 Info；环境变量中的级别无效时忽略环境变量
+	config := LogConfig{
+		LogToConsole:    true,
+		LevelForConsole: slog.LevelInfo,
+	}
+	logger, err := NewLogger(config)
+	if err != nil {
+		config.IgnoreEnv = true
+		logger, _ = NewLogger(config)
+	}
+	defaultLogger.Store(logger)
+}
+
+// SetDefault 设置包级别函数（Info、Warn 等）使用的默认 logger
+func SetDefault(logger *Logger) {
+	if logger != nil {
+		defaultLogger.Store(logger)
+	}
+}
+
+// Default 返回默认 logger
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+func Info(msg string, args ...any) {
+	Default().log(context.Background(), slog.LevelInfo, msg, args...)
+}
+
+func Warn(msg string, args ...any) {
+	Default().log(context.Background(), slog.LevelWarn, msg, args...)
+}
+
+func Error(msg string, args ...any) {
+	Default().log(context.Background(), slog.LevelError, msg, args...)
+}
+
+func Debug(msg string, args ...any) {
+	Default().log(context.Background(), slog.LevelDebug, msg, args...)
+}
@@ -0,0 +1,72 @@
+package xslog
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// DiskFullRetry 的默认值
+const defaultDiskFullRetry = 30 * time.Second
+
+// handleDiskFull 在写入返回 ENOSPC 时暂停文件输出，调用方持有 w.mu。
+// 暂停期间的记录交给 divert（写到 LogConfig.Fallback 或丢弃），每隔 retry 重新打开文件再试一次，
+// 暂停时通过 OnError 报告，之后在 InternalLog 中定期提醒
+func (w *fileWriter) handleDiskFull(err error) bool {
+	if !errors.Is(err, syscall.ENOSPC) {
+		return false
+	}
+	if w.resuming {
+		w.resuming = false
+		w.report(fmt.Errorf("log disk still full, file output remains suspended: %w", err))
+	} else {
+		w.report(fmt.Errorf("log disk full, file output suspended for %s: %w", w.retry, err))
+	}
+	w.suspended = true
+	w.retryAt = time.Now().Add(w.retry)
+	return true
+}
+
+// divert 处理暂停期间没有写入日志文件的记录：配置了 LogConfig.Fallback 时改写到那里，否则丢弃；
+// 同时在 InternalLog 中提醒文件输出仍处于暂停状态（每 10 秒最多一次），调用方持有 w.mu
+func (w *fileWriter) divert(p []byte) {
+	w.suspendedDrops++
+	if w.fallback != nil {
+		_, _ = w.fallback.Write(p)
+		w.self.printf("disk full suspended", "log disk full, file output suspended, %d records written to the fallback so far", w.suspendedDrops)
+		return
+	}
+	w.self.printf("disk full suspended", "log disk full, file output suspended, %d records dropped so far", w.suspendedDrops)
+}
+
+// resume 在暂停期满后重新打开日志文件，返回是否可以继续写入，调用方持有 w.mu
+func (w *fileWriter) resume() bool {
+	if time.Now().Before(w.retryAt) {
+		return false
+	}
+	// 重新打开，丢弃可能处于错误状态的缓冲和文件句柄
+	file, err := openLogFile(w.file.Name())
+	if err != nil {
+		w.retryAt = time.Now().Add(w.retry)
+		w.report(fmt.Errorf("log file reopen failed, file output remains suspended: %w", err))
+		return false
+	}
+	_ = w.file.Close()
+	w.file = file
+	if w.buf != nil {
+		w.buf.Reset(file)
+	}
+	w.suspended, w.resuming = false, true
+	return true
+}
+
+// resumed 在暂停后第一次写入成功时提示丢弃的记录数，调用方持有 w.mu
+func (w *fileWriter) resumed() {
+	if !w.resuming {
+		return
+	}
+	w.resuming = false
+	w.self.printf("disk full", "file output resumed, %d records were not written to the log file while the disk was full", w.suspendedDrops)
+	w.suspendedDrops = 0
+}
@@ -0,0 +1,186 @@
+package xslog
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// 单个加密帧的最大长度，超过时认为文件已损坏
+const maxEncryptedFrame = 64 << 20
+
+// ErrDecrypt 表示加密日志无法解密：密钥不对或内容被改动
+var ErrDecrypt = errors.New("failed to decrypt log record")
+
+// EncryptedWriter 用 AES-GCM 加密每次写入的内容（通常是一条记录），写成独立的帧：
+// 4 字节大端长度、12 字节随机 nonce、密文和认证标签。帧之间互不依赖，可以追加到已有文件，
+// 用 NewDecryptReader 读回明文。可以作为 Output.Writer 使用
+type EncryptedWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	aead  cipher.AEAD
+	frame []byte
+}
+
+// NewEncryptedWriter 返回写入 w 的 EncryptedWriter，key 为 16、24 或 32 字节，分别对应 AES-128/192/256
+func NewEncryptedWriter(w io.Writer, key []byte) (*EncryptedWriter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedWriter{w: w, aead: aead}, nil
+}
+
+// NewEncryptedFile 以追加方式打开 path（目录不存在时自动创建），返回写入它的 EncryptedWriter；
+// 用完后调用 Close 关闭文件
+func NewEncryptedFile(path string, key []byte) (*EncryptedWriter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedWriter{w: f, aead: aead}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// Write 把 p 加密为一帧写出；p 超过一帧能容纳的大小（maxEncryptedFrame）时，
+// 尽量在换行处拆成多帧，解密后的内容不变
+func (e *EncryptedWriter) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	max := maxEncryptedFrame - e.aead.NonceSize() - e.aead.Overhead()
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > max {
+			chunk = p[:max]
+			if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+				chunk = chunk[:i+1]
+			}
+		}
+		if err := e.writeFrame(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// writeFrame 把 p 加密为一帧写出，调用方持有 e.mu
+func (e *EncryptedWriter) writeFrame(p []byte) error {
+	nonceSize := e.aead.NonceSize()
+	size := nonceSize + len(p) + e.aead.Overhead()
+	e.frame = append(e.frame[:0], make([]byte, 4+nonceSize)...)
+	binary.BigEndian.PutUint32(e.frame, uint32(size))
+	if _, err := rand.Read(e.frame[4:]); err != nil {
+		return err
+	}
+	e.frame = e.aead.Seal(e.frame, e.frame[4:], p, nil)
+	// 整帧一次写出，避免与其他进程的追加交错
+	_, err := e.w.Write(e.frame)
+	if cap(e.frame) > maxPooledBuffer {
+		e.frame = nil
+	}
+	return err
+}
+
+// Close 在底层 writer 实现了 io.Closer 时关闭它
+func (e *EncryptedWriter) Close() error {
+	if c, ok := e.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// decryptReader 逐帧解密 EncryptedWriter 写出的内容
+type decryptReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	frame []byte
+	plain []byte // 当前帧中还没有读出的明文
+}
+
+// NewDecryptReader 返回从 r 读出 EncryptedWriter 写入内容的明文的 reader，
+// 对日志文件来说就是原来的 JSON 行。帧被改动或密钥不对时 Read 返回包装 ErrDecrypt 的错误，
+// 文件末尾不完整的帧（如写入时进程崩溃）返回 io.ErrUnexpectedEOF
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: r, aead: aead}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+func (d *decryptReader) next() error {
+	var hdr [4]byte
+	if _, err := io.ReadFull(d.r, hdr[:]); err != nil {
+		return err
+	}
+	size := binary.BigEndian.Uint32(hdr[:])
+	nonceSize := d.aead.NonceSize()
+	if size < uint32(nonceSize+d.aead.Overhead()) || size > maxEncryptedFrame {
+		return fmt.Errorf("invalid frame size %d: %w", size, ErrDecrypt)
+	}
+	if cap(d.frame) < int(size) {
+		d.frame = make([]byte, size)
+	}
+	d.frame = d.frame[:size]
+	if _, err := io.ReadFull(d.r, d.frame); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	plain, err := d.aead.Open(d.frame[nonceSize:nonceSize], d.frame[:nonceSize], d.frame[nonceSize:], nil)
+	if err != nil {
+		return ErrDecrypt
+	}
+	d.plain = plain
+	return nil
+}
+
+// OpenEncryptedFile 打开 NewEncryptedFile 写出的文件，返回读出明文的 reader，用完后调用 Close
+func OpenEncryptedFile(path string, key []byte) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewDecryptReader(f, key)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
@@ -0,0 +1,140 @@
+package xslog
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 可以覆盖初始级别的环境变量，XSLOG_CONSOLE_LEVEL/XSLOG_FILE_LEVEL 优先于 LOG_LEVEL
+const (
+	EnvLogLevel     = "LOG_LEVEL"
+	EnvConsoleLevel = "XSLOG_CONSOLE_LEVEL"
+	EnvFileLevel    = "XSLOG_FILE_LEVEL"
+)
+
+// NewLoggerFromEnv 读取的其他环境变量
+const (
+	EnvConsole         = "XSLOG_CONSOLE"           // 是否输出到控制台，默认 true
+	EnvFile            = "XSLOG_FILE"              // 是否输出到文件，设置了 XSLOG_FILE_PATH 时默认 true
+	EnvFilePath        = "XSLOG_FILE_PATH"         // 日志文件路径
+	EnvFileFormat      = "XSLOG_FILE_FORMAT"       // 日志文件的格式，名称与配置文件的 file.format 相同，如 "ecs"
+	EnvStackTraceLevel = "XSLOG_STACK_TRACE_LEVEL" // 附加调用栈的最低级别
+	EnvLevels          = "XSLOG_LEVELS"            // 按名称覆盖级别，如 "db.*=debug,http=warn"
+	EnvSigningKey      = "XSLOG_SIGNING_KEY"       // 日志文件签名用的 Ed25519 私钥，格式见 ParseSigningKey
+)
+
+// NewLoggerFromEnv 完全根据 XSLOG_* 环境变量（以及 LOG_LEVEL）创建 logger，
+// 适合 12-factor 风格的部署
+func NewLoggerFromEnv() (*Logger, error) {
+	config := LogConfig{LogToConsole: true}
+	if err := envBool(EnvConsole, &config.LogToConsole); err != nil {
+		return nil, err
+	}
+	if path := os.Getenv(EnvFilePath); path != "" {
+		config.LogFilePath = path
+		config.LogToFile = true
+	}
+	if err := envBool(EnvFile, &config.LogToFile); err != nil {
+		return nil, err
+	}
+	if config.LogToFile && config.LogFilePath == "" {
+		return nil, fmt.Errorf("%s is required when %s is true", EnvFilePath, EnvFile)
+	}
+	if value := os.Getenv(EnvFileFormat); value != "" {
+		format, err := lookupFormat(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvFileFormat, err)
+		}
+		config.FileFormat = format
+	}
+	if value := os.Getenv(EnvStackTraceLevel); value != "" {
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvStackTraceLevel, err)
+		}
+		config.StackTraceLevel = level
+	}
+	if value := os.Getenv(EnvSigningKey); value != "" {
+		key, err := ParseSigningKey([]byte(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvSigningKey, err)
+		}
+		config.FileSigningKey = key
+	}
+	rules, err := envLevelRules()
+	if err != nil {
+		return nil, err
+	}
+
+	ml, err := NewLogger(config)
+	if err != nil {
+		return nil, err
+	}
+	ml.levelRules.replace(rules)
+	return ml, nil
+}
+
+func envBool(key string, dst *bool) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: expected a boolean, got %q", key, value)
+	}
+	*dst = b
+	return nil
+}
+
+// envLevelRules 解析 XSLOG_LEVELS，格式为逗号分隔的 pattern=level
+func envLevelRules() (map[string]slog.Level, error) {
+	rules := map[string]slog.Level{}
+	for _, item := range strings.Split(os.Getenv(EnvLevels), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, value, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("invalid %s: expected pattern=level, got %q", EnvLevels, item)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s: %w", EnvLevels, pattern, err)
+		}
+		rules[strings.TrimSpace(pattern)] = level
+	}
+	return rules, nil
+}
+
+// applyEnvLevels 用环境变量中的级别覆盖 config 中的初始级别
+func applyEnvLevels(config *LogConfig) error {
+	if config.IgnoreEnv {
+		return nil
+	}
+	for _, env := range []struct {
+		key     string
+		targets []*slog.Level
+	}{
+		{EnvLogLevel, []*slog.Level{&config.LevelForConsole, &config.LevelForFile}},
+		{EnvConsoleLevel, []*slog.Level{&config.LevelForConsole}},
+		{EnvFileLevel, []*slog.Level{&config.LevelForFile}},
+	} {
+		value, ok := os.LookupEnv(env.key)
+		if !ok || value == "" {
+			continue
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", env.key, err)
+		}
+		for _, target := range env.targets {
+			*target = level
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package xslog
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Err 输出的属性名
+const (
+	ErrorKey      = "error"
+	ErrorTypeKey  = "error_type"
+	ErrorStackKey = "error_stack"
+
+	ErrorCausesKey = "error_causes" // LogConfig.ErrorCauses 展开的错误链
+)
+
+// 展开错误链时最多记录的原因数，防止循环或过大的错误树
+const maxErrorCauses = 32
+
+// Err 返回统一格式的错误属性：error 为错误信息，error_type 为错误的具体类型。
+// err 为 nil 时返回空属性，不会输出任何内容
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any("", errorValue{err: err})
+}
+
+// ErrWithStack 与 Err 相同，另外附加调用处的堆栈（error_stack）
+func ErrWithStack(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any("", errorValue{err: err, stack: captureStack(1)})
+}
+
+// errorValue 在 JSON 中展开为 error/error_type/error_stack 三个字段，
+// 控制台 handler 会识别它并输出为 error=信息 error_type=类型，堆栈和错误链缩进显示在日志行之后
+type errorValue struct {
+	err   error
+	stack stackValue
+	typ   string // 不为空时代替 err 的类型，用于改写过信息的错误
+
+	causes []errorCause // LogConfig.ErrorCauses 展开的错误链，不含 err 本身
+}
+
+// errorCause 是错误链中的一个原因，在 JSON 中输出为 {"error": ..., "error_type": ...}
+type errorCause struct {
+	Message string `json:"error"`
+	Type    string `json:"error_type"`
+}
+
+// errorCauses 按深度优先顺序展开 err 包装的错误，包括 errors.Join 和
+// 实现了 Unwrap() []error 的多个错误，不含 err 本身
+func errorCauses(err error) []errorCause {
+	var causes []errorCause
+	var walk func(err error)
+	walk = func(err error) {
+		var next []error
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			next = []error{x.Unwrap()}
+		case interface{ Unwrap() []error }:
+			next = x.Unwrap()
+		}
+		for _, e := range next {
+			if e == nil {
+				continue
+			}
+			if len(causes) == maxErrorCauses {
+				return
+			}
+			causes = append(causes, errorCause{Message: e.Error(), Type: fmt.Sprintf("%T", e)})
+			walk(e)
+		}
+	}
+	walk(err)
+	return causes
+}
+
+func (v errorValue) errType() string {
+	if v.typ != "" {
+		return v.typ
+	}
+	return fmt.Sprintf("%T", v.err)
+}
+
+func (v errorValue) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String(ErrorKey, v.err.Error()),
+		slog.String(ErrorTypeKey, v.errType()),
+	}
+	if len(v.causes) > 0 {
+		attrs = append(attrs, slog.Any(ErrorCausesKey, v.causes))
+	}
+	if len(v.stack) > 0 {
+		attrs = append(attrs, slog.Any(ErrorStackKey, []string(v.stack)))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// causeExpander 为 Err 和 ErrWithStack 的错误展开错误链，见 LogConfig.ErrorCauses
+type causeExpander struct{}
+
+func (causeExpander) attr(a slog.Attr) (slog.Attr, bool) {
+	if ev, ok := a.Value.Any().(errorValue); ok {
+		if ev.causes != nil {
+			return a, false
+		}
+		if ev.causes = errorCauses(ev.err); len(ev.causes) == 0 {
+			return a, false
+		}
+		return slog.Any(a.Key, ev), true
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if g, changed := mapGroup(a.Value, causeExpander{}.attr); changed {
+			return slog.Attr{Key: a.Key, Value: g}, true
+		}
+	}
+	return a, false
+}
@@ -0,0 +1,54 @@
+package xslog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// FallbackAfter 的默认值
+const defaultFallbackAfter = 3
+
+// fallbackWriter 在 primary 连续失败 after 次之后，把写失败的内容改写到 fallback，
+// primary 恢复（写入成功）后自动切回。primary 的错误仍然返回，由 OnError 报告
+type fallbackWriter struct {
+	primary  io.Writer
+	fallback *lockedWriter
+	after    int64
+	failures atomic.Int64 // 连续失败次数
+}
+
+func (w *fallbackWriter) Write(p []byte) (int, error) {
+	n, err := w.primary.Write(p)
+	if err == nil {
+		w.failures.Store(0)
+		return n, nil
+	}
+	if w.failures.Add(1) >= w.after {
+		_, _ = w.fallback.Write(p)
+	}
+	return n, err
+}
+
+// lockedWriter 让多个输出共用的 fallback 不会交错写入
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// withFallback 为 dst 加上 config.Fallback，未配置时原样返回
+func withFallback(dst io.Writer, fallback *lockedWriter, after int) io.Writer {
+	if fallback == nil {
+		return dst
+	}
+	if after <= 0 {
+		after = defaultFallbackAfter
+	}
+	return &fallbackWriter{primary: dst, fallback: fallback, after: int64(after)}
+}
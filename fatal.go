@@ -0,0 +1,24 @@
+package xslog
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// exit 便于测试时替换
+var exit = os.Exit
+
+// Fatal 以 LevelFatal 记录日志，刷新所有输出后调用 os.Exit(1)
+func (ml *Logger) Fatal(msg string, args ...any) {
+	ml.log(context.Background(), LevelFatal, msg, args...)
+	_ = ml.Flush()
+	exit(1)
+}
+
+// Panic 以 Error 级别记录日志，然后以 msg 触发 panic
+func (ml *Logger) Panic(msg string, args ...any) {
+	ml.log(context.Background(), slog.LevelError, msg, args...)
+	_ = ml.Flush()
+	panic(msg)
+}
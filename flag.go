@@ -0,0 +1,90 @@
+package xslog
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/spf13/pflag"
+)
+
+// LevelFlags 保存命令行中的日志级别参数：-v 为 Debug，-vv 为 Trace，
+// --log-level 直接指定级别并优先于 -v
+type LevelFlags struct {
+	verbose int
+	level   string
+}
+
+// RegisterFlags 在标准库 flag.FlagSet 上注册 -v、-vv 和 -log-level（也可写作 --log-level），
+// fs 为 nil 时使用 flag.CommandLine
+func RegisterFlags(fs *flag.FlagSet) *LevelFlags {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	f := &LevelFlags{}
+	fs.Var(&verboseFlag{count: &f.verbose, n: 1}, "v", "verbose logging (debug)")
+	fs.Var(&verboseFlag{count: &f.verbose, n: 2}, "vv", "very verbose logging (trace)")
+	fs.StringVar(&f.level, "log-level", "", "log level: trace, debug, info, warn, error, or a level such as warn+2")
+	return f
+}
+
+// RegisterPFlags 在 pflag.FlagSet 上注册 -v/--verbose（可重复，如 -vv）和 --log-level
+func RegisterPFlags(fs *pflag.FlagSet) *LevelFlags {
+	f := &LevelFlags{}
+	fs.CountVarP(&f.verbose, "verbose", "v", "verbose logging, repeat for more (-v debug, -vv trace)")
+	fs.StringVar(&f.level, "log-level", "", "log level: trace, debug, info, warn, error, or a level such as warn+2")
+	return f
+}
+
+// Level 返回参数对应的级别，没有指定任何参数时 ok 为 false
+func (f *LevelFlags) Level() (level slog.Level, ok bool, err error) {
+	if f.level != "" {
+		level, err = ParseLevel(f.level)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid --log-level: %w", err)
+		}
+		return level, true, nil
+	}
+	if f.verbose > 0 {
+		return slog.LevelInfo - slog.Level(4*f.verbose), true, nil
+	}
+	return 0, false, nil
+}
+
+// Apply 把参数对应的级别设置到控制台和文件，没有指定参数时保持 logger 原来的级别
+func (f *LevelFlags) Apply(ml *Logger) error {
+	level, ok, err := f.Level()
+	if err != nil || !ok {
+		return err
+	}
+	ml.SetConsoleLevel(level)
+	ml.SetFileLevel(level)
+	return nil
+}
+
+// verboseFlag 是布尔形式的 flag，出现时把 count 设为 n
+type verboseFlag struct {
+	count *int
+	n     int
+}
+
+func (v *verboseFlag) String() string {
+	if v.count == nil {
+		return "false"
+	}
+	return strconv.FormatBool(*v.count >= v.n)
+}
+
+func (v *verboseFlag) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if b && *v.count < v.n {
+		*v.count = v.n
+	}
+	return nil
+}
+
+func (v *verboseFlag) IsBoolFlag() bool { return true }
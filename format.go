@@ -0,0 +1,162 @@
+package xslog
+
+import (
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// 记录链路追踪信息的属性名，ECS 等格式会把它们映射到各自的字段
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// Format 是日志文件或 Output 的编码格式，nil 表示 xslog 默认的 JSON 格式。
+// 同一种格式（和 PIIPolicy）的输出共用一次编码
+type Format interface {
+	// encoder 返回把一条记录编码后写到 w 的 handler，级别由调用方判断
+	encoder(w io.Writer) slog.Handler
+}
+
+// isJSONFormat 报告 f 是否把每条记录编码为一个 JSON 对象，哈希链和签名只能用于这些格式
+func isJSONFormat(f Format) bool {
+	switch f.(type) {
+	case nil, jsonFormat, ecsFormat, GCPFormat:
+		return true
+	}
+	return false
+}
+
+// jsonFormat 是默认格式
+type jsonFormat struct{}
+
+func (jsonFormat) encoder(w io.Writer) slog.Handler {
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       slog.Level(-1 << 31),
+		ReplaceAttr: replaceLevelName,
+	})
+}
+
+// ECSVersion 是 FormatECS 输出的 ecs.version
+const ECSVersion = "8.11.0"
+
+// FormatECS 按 Elastic Common Schema 命名字段：@timestamp、log.level、message、log.logger、
+// error.message、error.type、error.stack_trace、trace.id、span.id，记录写入 Elasticsearch 时不需要 ingest pipeline
+var FormatECS Format = ecsFormat{}
+
+type ecsFormat struct{}
+
+// ecsKeys 是顶层属性到 ECS 字段的映射
+var ecsKeys = map[string]string{
+	slog.TimeKey:    "@timestamp",
+	slog.LevelKey:   "log.level",
+	slog.MessageKey: "message",
+	LoggerNameKey:   "log.logger",
+	ErrorKey:        "error.message",
+	ErrorTypeKey:    "error.type",
+	ErrorStackKey:   "error.stack_trace",
+	TraceIDKey:      "trace.id",
+	SpanIDKey:       "span.id",
+	HostKey:         "host.hostname",
+	PIDKey:          "process.pid",
+	ExecutableKey:   "process.executable",
+	VersionKey:      "service.version",
+}
+
+func (ecsFormat) encoder(w io.Writer) slog.Handler {
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: slog.Level(-1 << 31),
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			name, ok := ecsKeys[a.Key]
+			if !ok {
+				return a
+			}
+			switch a.Key {
+			case slog.LevelKey:
+				if level, ok := a.Value.Any().(slog.Level); ok {
+					return slog.String(name, strings.ToLower(levelName(level)))
+				}
+			case ErrorStackKey:
+				// ECS 的 error.stack_trace 是一个字符串
+				if frames, ok := a.Value.Any().([]string); ok {
+					return slog.String(name, strings.Join(frames, "\n"))
+				}
+			}
+			return slog.Attr{Key: name, Value: a.Value}
+		},
+	})
+	return h.WithAttrs([]slog.Attr{slog.String("ecs.version", ECSVersion)})
+}
+
+// GCPFormat 按 Cloud Logging 的结构化日志约定输出：severity、time、message、
+// logging.googleapis.com/trace、logging.googleapis.com/spanId 和 logging.googleapis.com/sourceLocation，
+// 适合输出到 GKE 等环境的标准输出
+//
+//	xslog.Output{Writer: os.Stdout, Format: xslog.GCPFormat{ProjectID: "my-project"}}
+type GCPFormat struct {
+	// ProjectID 不为空时，trace_id 输出为 "projects/<ProjectID>/traces/<trace_id>"，
+	// 日志查看器才能关联到 Cloud Trace
+	ProjectID string
+}
+
+// gcpSeverity 把级别映射到 Cloud Logging 的 severity
+func gcpSeverity(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return "DEBUG"
+	case level < LevelNotice:
+		return "INFO"
+	case level < slog.LevelWarn:
+		return "NOTICE"
+	case level < slog.LevelError:
+		return "WARNING"
+	case level < LevelFatal:
+		return "ERROR"
+	case level < LevelFatal+4:
+		return "CRITICAL"
+	case level < LevelFatal+8:
+		return "ALERT"
+	}
+	return "EMERGENCY"
+}
+
+func (f GCPFormat) encoder(w io.Writer) slog.Handler {
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     slog.Level(-1 << 31),
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			switch a.Key {
+			case slog.LevelKey:
+				if level, ok := a.Value.Any().(slog.Level); ok {
+					return slog.String("severity", gcpSeverity(level))
+				}
+			case slog.MessageKey:
+				return slog.Attr{Key: "message", Value: a.Value}
+			case slog.SourceKey:
+				if src, ok := a.Value.Any().(*slog.Source); ok {
+					return slog.Group("logging.googleapis.com/sourceLocation",
+						slog.String("file", src.File),
+						slog.String("line", strconv.Itoa(src.Line)),
+						slog.String("function", src.Function))
+				}
+			case TraceIDKey:
+				trace := a.Value.String()
+				if f.ProjectID != "" {
+					trace = "projects/" + f.ProjectID + "/traces/" + trace
+				}
+				return slog.String("logging.googleapis.com/trace", trace)
+			case SpanIDKey:
+				return slog.Attr{Key: "logging.googleapis.com/spanId", Value: a.Value}
+			}
+			return a
+		},
+	})
+}
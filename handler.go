@@ -0,0 +1,59 @@
+package xslog
+
+import (
+	"context"
+	"log"
+	"log/slog"
+)
+
+// Handler 返回同时输出到控制台和文件的 slog.Handler，
+// 配合 slog.SetDefault(slog.New(logger.Handler())) 可以让使用 slog 的第三方库也走 xslog 的输出
+func (ml *Logger) Handler() slog.Handler {
+	return &loggerHandler{ml: ml}
+}
+
+// AsSlog 返回基于 Handler 的 *slog.Logger
+func (ml *Logger) AsSlog() *slog.Logger {
+	return slog.New(ml.Handler())
+}
+
+// StdLogger 返回把每条消息以 level 级别记录到 ml 的标准库 *log.Logger，
+// 用于 http.Server.ErrorLog 等只接受 *log.Logger 的地方
+//
+//	srv := &http.Server{ErrorLog: logger.StdLogger(slog.LevelWarn)}
+func (ml *Logger) StdLogger(level slog.Level) *log.Logger {
+	return slog.NewLogLogger(ml.Handler(), level)
+}
+
+// loggerHandler 把 slog 的调用转交给 Logger，共享其级别、开关和覆盖规则
+type loggerHandler struct {
+	ml *Logger
+}
+
+func (h *loggerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.ml.enabled(ctx, level)
+}
+
+func (h *loggerHandler) Handle(ctx context.Context, r slog.Record) error {
+	// slog.Logger 用 time.Now 构造记录，配置了 Clock 时改用它的时间
+	if h.ml.clock != nil {
+		r.Time = h.ml.clock()
+	}
+	return h.ml.handle(ctx, r)
+}
+
+func (h *loggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	child := *h.ml
+	child.handler = h.ml.handler.withAttrs(h.ml.sizes.attrs(h.ml.transformAttrs(attrs)))
+	return &loggerHandler{ml: &child}
+}
+
+func (h *loggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &loggerHandler{ml: h.ml.WithGroup(name)}
+}
@@ -0,0 +1,145 @@
+package xslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// HealthChecker 由可以检查自身是否可用的输出实现，如 HTTPWriter、CircuitBreaker 和 Spool；
+// 自定义的 Output.Writer 实现它之后也会出现在 Logger.HealthCheck 的结果中
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheck 检查每个启用的输出是否可用：日志文件和审计文件是否仍可写入（没有被删除、没有因磁盘写满暂停），
+// 实现了 HealthChecker 的输出（如远程服务）是否可达。返回输出名称到检查结果的 map，nil 表示可用
+func (ml *Logger) HealthCheck(ctx context.Context) map[string]error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	status := map[string]error{}
+	if ml.consoleOn.Load() {
+		status[SinkConsole] = checkWriter(ctx, ml.config.ConsoleWriter)
+	}
+	if ml.fileOn.Load() {
+		status[SinkFile] = ml.fileWriter.healthCheck()
+	}
+	for _, o := range ml.config.Outputs {
+		status[o.Name] = checkWriter(ctx, o.Writer)
+	}
+	if cfg := ml.config.Audit; cfg != nil {
+		if ml.auditWriter != nil {
+			status[SinkAudit] = ml.auditWriter.healthCheck()
+		} else {
+			status[SinkAudit] = checkWriter(ctx, cfg.Writer)
+		}
+	}
+	return status
+}
+
+// HealthHandler 返回用于就绪探针的 http.Handler：所有输出可用时返回 200，否则返回 503，
+// 响应体为各输出状态的 JSON，如 {"file":"ok","remote":"log output circuit open"}
+func (ml *Logger) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := ml.HealthCheck(r.Context())
+		body, code := make(map[string]string, len(status)), http.StatusOK
+		for _, name := range sortedKeys(status) {
+			if err := status[name]; err != nil {
+				body[name], code = err.Error(), http.StatusServiceUnavailable
+			} else {
+				body[name] = "ok"
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
+
+func checkWriter(ctx context.Context, w any) error {
+	if hc, ok := w.(HealthChecker); ok {
+		return hc.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// healthCheck 检查日志文件是否仍可写入
+func (w *fileWriter) healthCheck() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return errors.New("log file not open")
+	}
+	if w.suspended {
+		return errors.New("log disk full, file output suspended")
+	}
+	return checkFile(w.file)
+}
+
+// checkFile 检查 file 所在路径仍指向它并且可以打开写入
+func checkFile(file *os.File) error {
+	open, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	onDisk, err := os.Stat(file.Name())
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(open, onDisk) {
+		return fmt.Errorf("%s was replaced or moved", file.Name())
+	}
+	f, err := os.OpenFile(file.Name(), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// HealthCheck 用 HEAD 请求检查日志收集服务是否可达，网络错误和 5xx 视为不可用；
+// 不支持 HEAD 的服务返回的 4xx 也说明服务可达
+func (w *HTTPWriter) HealthCheck(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, w.cfg.URL, nil)
+	if err != nil {
+		return err
+	}
+	for k, vs := range w.cfg.Header {
+		req.Header[k] = vs
+	}
+	resp, err := w.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("log collector %s: %s", w.cfg.URL, resp.Status)
+	}
+	return nil
+}
+
+// HealthCheck 在熔断期间返回 ErrCircuitOpen，否则检查底层输出（如果它实现了 HealthChecker）
+func (b *CircuitBreaker) HealthCheck(ctx context.Context) error {
+	if b.Open() {
+		return ErrCircuitOpen
+	}
+	return checkWriter(ctx, b.w)
+}
+
+// HealthCheck 检查段文件目录是否可以写入；远程服务不可达时 Spool 仍然可用，不影响结果
+func (s *Spool) HealthCheck(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active != nil {
+		return checkFile(s.active)
+	}
+	f, err := os.CreateTemp(s.dir, ".health-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,79 @@
+package xslog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPConfig 的默认值
+const (
+	defaultHTTPContentType = "application/x-ndjson"
+	defaultHTTPTimeout     = 10 * time.Second
+)
+
+// HTTPConfig 配置 HTTPWriter
+type HTTPConfig struct {
+	URL         string
+	Header      http.Header  // 附加的请求头，如认证信息
+	ContentType string       // 默认 application/x-ndjson
+	Client      *http.Client // 默认使用 Timeout 为 10s 的 client
+	Retry       RetryPolicy  // 网络错误、429 和 5xx 时重试
+}
+
+// HTTPWriter 把每次 Write 的内容（一条或多条 JSON 记录）POST 到日志收集服务，
+// 可作为 Output.Writer 使用。Write 在重试期间阻塞，建议配合 AsyncConfig 使用，
+// 开启 BatchSize 时多条记录合并为一个请求
+type HTTPWriter struct {
+	cfg HTTPConfig
+}
+
+func NewHTTPWriter(cfg HTTPConfig) *HTTPWriter {
+	if cfg.ContentType == "" {
+		cfg.ContentType = defaultHTTPContentType
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return &HTTPWriter{cfg: cfg}
+}
+
+func (w *HTTPWriter) Write(p []byte) (int, error) {
+	err := w.cfg.Retry.Do(context.Background(), func() error {
+		return w.post(p)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *HTTPWriter) post(p []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.cfg.URL, bytes.NewReader(p))
+	if err != nil {
+		return Permanent(err)
+	}
+	for k, vs := range w.cfg.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", w.cfg.ContentType)
+
+	resp, err := w.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("log collector %s: %s", w.cfg.URL, resp.Status)
+	default:
+		return Permanent(fmt.Errorf("log collector %s: %s", w.cfg.URL, resp.Status))
+	}
+}
@@ -0,0 +1,94 @@
+package xslog
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Bytes 返回字节数属性，JSON 中为原始数字，控制台显示为二进制单位，如 "3.4MiB"
+//
+//	logger.Info("upload done", xslog.Bytes("size", n), xslog.Duration("took", time.Since(start)))
+func Bytes(key string, n int64) slog.Attr {
+	return slog.Any(key, byteSize(n))
+}
+
+// Duration 返回时长属性，JSON 中与 slog.Duration 一样为纳秒数，
+// 控制台保留三位有效数字，如 "1.23s"、"450µs"
+func Duration(key string, d time.Duration) slog.Attr {
+	return slog.Any(key, humanDuration(d))
+}
+
+// byteSize 在 JSON 中输出为数字，控制台显示为 "3.4MiB"
+type byteSize int64
+
+func (n byteSize) LogValue() slog.Value {
+	return slog.Int64Value(int64(n))
+}
+
+// humanDuration 在 JSON 中输出为纳秒数，控制台显示为约三位有效数字的时长
+type humanDuration time.Duration
+
+func (d humanDuration) LogValue() slog.Value {
+	return slog.DurationValue(time.Duration(d))
+}
+
+// isHumanValue 报告 v 是否是 Bytes 或 Duration 的值，控制台需要在求值之前识别它们
+func isHumanValue(v slog.Value) bool {
+	if v.Kind() != slog.KindLogValuer {
+		return false
+	}
+	switch v.Any().(type) {
+	case byteSize, humanDuration:
+		return true
+	}
+	return false
+}
+
+// appendHumanValue 追加 Bytes 和 Duration 的控制台格式，调用方先用 isHumanValue 判断
+func appendHumanValue(buf []byte, v slog.Value) []byte {
+	switch x := v.Any().(type) {
+	case byteSize:
+		return appendByteSize(buf, int64(x))
+	case humanDuration:
+		return append(buf, roundDuration(time.Duration(x)).String()...)
+	}
+	return buf
+}
+
+// appendByteSize 按 1024 进位，不足 1KiB 时显示字节数，否则保留一位小数
+func appendByteSize(buf []byte, n int64) []byte {
+	const units = "KMGTPE"
+	// 不对 n 取反，-n 在 math.MinInt64 时溢出
+	if n > -1024 && n < 1024 {
+		buf = strconv.AppendInt(buf, n, 10)
+		return append(buf, 'B')
+	}
+	f := float64(n)
+	i := -1
+	for i < len(units)-1 && math.Abs(f) >= 1024 {
+		f /= 1024
+		i++
+	}
+	// 舍入到一位小数后满 1024 时进到下一个单位，如 1048575 显示为 1.0MiB 而不是 1024.0KiB
+	if i < len(units)-1 && math.Abs(math.Round(f*10)) >= 10240 {
+		f /= 1024
+		i++
+	}
+	buf = strconv.AppendFloat(buf, f, 'f', 1, 64)
+	return append(buf, units[i], 'i', 'B')
+}
+
+// roundDuration 把 d 舍入到约三位有效数字，超过一秒的部分最多精确到秒
+func roundDuration(d time.Duration) time.Duration {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	unit := time.Duration(1)
+	for abs/unit >= 1000 && unit < time.Second {
+		unit *= 10
+	}
+	return d.Round(unit)
+}
@@ -0,0 +1,27 @@
+package xslog
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAppendByteSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{1023, "1023B"},
+		{-1023, "-1023B"},
+		{1024, "1.0KiB"},
+		{1048524, "1023.9KiB"},
+		{1048575, "1.0MiB"},
+		{-1048575, "-1.0MiB"},
+		{math.MaxInt64, "8.0EiB"},
+		{math.MinInt64, "-8.0EiB"},
+	}
+	for _, tt := range tests {
+		if got := string(appendByteSize(nil, tt.n)); got != tt.want {
+			t.Errorf("appendByteSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package xslog
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Lazy 返回一个延迟求值的属性值，只有当某个输出确实要记录这条日志时才会调用 fn，
+// 多个输出共享同一次调用结果：
+//
+//	logger.Debug("state", "snapshot", xslog.Lazy(func() any { return dump(state) }))
+func Lazy(fn func() any) slog.LogValuer {
+	return &lazyValue{fn: fn}
+}
+
+type lazyValue struct {
+	once  sync.Once
+	fn    func() any
+	value slog.Value
+}
+
+func (v *lazyValue) LogValue() slog.Value {
+	v.once.Do(func() {
+		v.value = slog.AnyValue(v.fn())
+	})
+	return v.value
+}
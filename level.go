@@ -0,0 +1,175 @@
+package xslog
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 内置之外的常用级别
+const (
+	LevelTrace  = slog.Level(-8)
+	LevelNotice = slog.Level(2)
+	LevelFatal  = slog.Level(12) // Fatal 使用的级别
+)
+
+// LevelSpec 描述一个级别的显示方式
+type LevelSpec struct {
+	Name  string // 完整名称，用于 JSON 输出，如 "TRACE"
+	Short string // 控制台显示的缩写，如 "TRC"，为空时取 Name 的前三个字母
+	Color int    // 控制台 ANSI 颜色码，为 0 时使用白色
+}
+
+var (
+	levelsMu sync.RWMutex
+	levels   = map[slog.Level]LevelSpec{
+		LevelTrace:      {Name: "TRACE", Short: "TRC", Color: 90},  // Gray
+		slog.LevelDebug: {Name: "DEBUG", Short: "DBG", Color: 35},  // Purple
+		slog.LevelInfo:  {Name: "INFO", Short: "INF", Color: 34},   // Blue
+		LevelNotice:     {Name: "NOTICE", Short: "NTC", Color: 36}, // Cyan
+		slog.LevelWarn:  {Name: "WARN", Short: "WRN", Color: 33},   // Yellow
+		slog.LevelError: {Name: "ERROR", Short: "ERR", Color: 31},  // Red
+		LevelFatal:      {Name: "FATAL", Short: "FTL", Color: 91},  // Bright Red
+	}
+)
+
+// RegisterLevel 注册或覆盖一个级别的名称和颜色，
+// 控制台和 JSON 输出都会使用注册的名称
+func RegisterLevel(level slog.Level, spec LevelSpec) {
+	spec.Name = strings.ToUpper(spec.Name)
+	if spec.Short == "" {
+		spec.Short = spec.Name
+		// 按字符截取，不切断多字节字符
+		if r := []rune(spec.Short); len(r) > 3 {
+			spec.Short = string(r[:3])
+		}
+	}
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	levels[level] = spec
+}
+
+func lookupLevel(level slog.Level) (LevelSpec, bool) {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	spec, ok := levels[level]
+	return spec, ok
+}
+
+func getLevelColor(level slog.Level) int {
+	if spec, ok := lookupLevel(level); ok && spec.Color != 0 {
+		return spec.Color
+	}
+	return 37 // Default White
+}
+
+func getLevelName(level slog.Level) string {
+	if spec, ok := lookupLevel(level); ok {
+		return spec.Short
+	}
+	return strings.ToUpper(level.String()[:3])
+}
+
+// LevelStyle 决定控制台中级别标签的写法，名称来自 RegisterLevel 注册的 LevelSpec
+type LevelStyle int
+
+const (
+	LevelStyleShort  LevelStyle = iota // LevelSpec.Short，如 "INF"，默认
+	LevelStyleFull                     // LevelSpec.Name，如 "INFO"
+	LevelStyleLetter                   // Short 的第一个字符，如 "I"
+)
+
+// label 返回 level 按这种写法的标签
+func (s LevelStyle) label(level slog.Level) string {
+	switch s {
+	case LevelStyleFull:
+		return levelName(level)
+	case LevelStyleLetter:
+		short := getLevelName(level)
+		_, size := utf8.DecodeRuneInString(short)
+		return short[:size]
+	}
+	return getLevelName(level)
+}
+
+// ParseLevelStyle 解析级别标签的写法：short、full 或 letter
+func ParseLevelStyle(s string) (LevelStyle, error) {
+	switch strings.ToLower(s) {
+	case "short", "":
+		return LevelStyleShort, nil
+	case "full":
+		return LevelStyleFull, nil
+	case "letter":
+		return LevelStyleLetter, nil
+	}
+	return 0, fmt.Errorf("unknown level style %q, expected short, full or letter", s)
+}
+
+// levelName 返回级别的完整名称，未注册的级别为 slog 的写法，如 "ERROR+2"
+func levelName(level slog.Level) string {
+	if spec, ok := lookupLevel(level); ok {
+		return spec.Name
+	}
+	return level.String()
+}
+
+// replaceLevelName 让 JSON 输出中注册过的级别显示为注册的名称（如 "FATAL"），而不是 "ERROR+4"
+func replaceLevelName(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.LevelKey {
+		return a
+	}
+	if level, ok := a.Value.Any().(slog.Level); ok {
+		if spec, ok := lookupLevel(level); ok {
+			return slog.String(slog.LevelKey, spec.Name)
+		}
+	}
+	return a
+}
+
+// ParseLevel 解析级别字符串，支持注册过的名称或缩写（不区分大小写）、
+// 带偏移的写法（如 "warn+2"、"info-4"）以及纯数字（如 "-8"）
+func ParseLevel(s string) (slog.Level, error) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return 0, fmt.Errorf("empty log level")
+	}
+	if n, err := strconv.Atoi(str); err == nil {
+		return slog.Level(n), nil
+	}
+
+	name, offset := str, 0
+	if i := strings.IndexAny(str, "+-"); i > 0 {
+		n, err := strconv.Atoi(str[i:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset in log level %q: %w", s, err)
+		}
+		name, offset = str[:i], n
+	}
+
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	for level, spec := range levels {
+		if strings.EqualFold(name, spec.Name) || strings.EqualFold(name, spec.Short) {
+			return level + slog.Level(offset), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected one of %s or a number", s, strings.Join(levelNames(), ", "))
+}
+
+// levelNames 按级别从低到高返回注册过的名称，调用方需持有 levelsMu
+func levelNames() []string {
+	registered := make([]slog.Level, 0, len(levels))
+	for level := range levels {
+		registered = append(registered, level)
+	}
+	sort.Slice(registered, func(i, j int) bool { return registered[i] < registered[j] })
+	names := make([]string, len(registered))
+	for i, level := range registered {
+		names[i] = strings.ToLower(levels[level].Name)
+	}
+	return names
+}
@@ -0,0 +1,142 @@
+package xslog
+
+import (
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// levelRule 是一条按名称覆盖最低级别的规则
+type levelRule struct {
+	pattern string
+	level   slog.Level
+}
+
+// levelRules 按 logger 名称（未命名时为调用方的包路径）覆盖最低级别
+type levelRules struct {
+	mu    sync.RWMutex
+	rules []levelRule
+}
+
+// SetLevelFor 为匹配 pattern 的 logger 设置最低级别，覆盖控制台和文件各自的级别。
+// pattern 可以是完整名称（"db.pool"）、以 * 结尾的前缀（"db.*" 同时匹配 "db" 及其子 logger），
+// 或单独的 "*" 匹配全部。未命名的 logger 以调用方的包路径参与匹配，
+// 如 "github.com/me/app/db" 或 "github.com/me/app/*"。
+// 多条规则同时命中时，最长的 pattern 生效
+func (ml *Logger) SetLevelFor(pattern string, level slog.Level) {
+	ml.levelRules.mu.Lock()
+	defer ml.levelRules.mu.Unlock()
+	for i := range ml.levelRules.rules {
+		if ml.levelRules.rules[i].pattern == pattern {
+			ml.levelRules.rules[i].level = level
+			return
+		}
+	}
+	ml.levelRules.rules = append(ml.levelRules.rules, levelRule{pattern: pattern, level: level})
+}
+
+// ClearLevelFor 删除 pattern 对应的覆盖规则
+func (ml *Logger) ClearLevelFor(pattern string) {
+	ml.levelRules.mu.Lock()
+	defer ml.levelRules.mu.Unlock()
+	for i, rule := range ml.levelRules.rules {
+		if rule.pattern == pattern {
+			ml.levelRules.rules = append(ml.levelRules.rules[:i], ml.levelRules.rules[i+1:]...)
+			return
+		}
+	}
+}
+
+// list 返回当前规则的副本
+func (lr *levelRules) list() []levelRule {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	return append([]levelRule(nil), lr.rules...)
+}
+
+// replace 用 rules 替换全部规则
+func (lr *levelRules) replace(rules map[string]slog.Level) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	lr.rules = lr.rules[:0]
+	for pattern, level := range rules {
+		lr.rules = append(lr.rules, levelRule{pattern: pattern, level: level})
+	}
+}
+
+// lookup 返回命中的覆盖级别，没有规则时直接返回，不解析调用方
+func (lr *levelRules) lookup(name string, pc uintptr) (slog.Level, bool) {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	if len(lr.rules) == 0 {
+		return 0, false
+	}
+	if name == "" {
+		name = callerPackage(pc)
+	}
+	var (
+		level   slog.Level
+		matched bool
+		best    = -1
+	)
+	for _, rule := range lr.rules {
+		if len(rule.pattern) > best && matchLevelPattern(rule.pattern, name) {
+			level, matched, best = rule.level, true, len(rule.pattern)
+		}
+	}
+	return level, matched
+}
+
+// empty 报告是否没有任何规则
+func (lr *levelRules) empty() bool {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	return len(lr.rules) == 0
+}
+
+// min 返回所有规则中最低的级别
+func (lr *levelRules) min() (slog.Level, bool) {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+	if len(lr.rules) == 0 {
+		return 0, false
+	}
+	min := lr.rules[0].level
+	for _, rule := range lr.rules[1:] {
+		if rule.level < min {
+			min = rule.level
+		}
+	}
+	return min, true
+}
+
+func matchLevelPattern(pattern, name string) bool {
+	prefix, wildcard := strings.CutSuffix(pattern, "*")
+	if !wildcard {
+		return pattern == name
+	}
+	if strings.HasPrefix(name, prefix) {
+		return true
+	}
+	// "db.*" 也匹配 "db" 本身
+	if trimmed := strings.TrimRight(prefix, "./"); trimmed != prefix {
+		return name == trimmed
+	}
+	return false
+}
+
+// callerPackage 从函数全名（如 "github.com/me/app/db.(*Pool).Get"）中取出包路径
+func callerPackage(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	// 使用 CallersFrames 以正确处理内联
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	full := frame.Function
+	slash := strings.LastIndexByte(full, '/') + 1
+	if dot := strings.IndexByte(full[slash:], '.'); dot >= 0 {
+		return full[:slash+dot]
+	}
+	return full
+}
@@ -0,0 +1,122 @@
+package xslog
+
+import (
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+)
+
+// TruncatedKey 是被截断的记录上附加的属性名，值为 true
+const TruncatedKey = "truncated"
+
+// SizeLimits 限制单条记录的大小，防止误把很大的内容（如整个响应体）写进日志；
+// 超出的部分被截掉，记录上附加 truncated=true。各项为 0 表示不限制
+//
+//	Limits: &xslog.SizeLimits{MaxMessageBytes: 4 << 10, MaxValueBytes: 16 << 10, MaxAttrs: 64}
+type SizeLimits struct {
+	MaxMessageBytes int // 消息的最大字节数
+	MaxValueBytes   int // 字符串、[]byte、error 和 fmt.Stringer 属性值（LogValuer 按求值结果）的最大字节数，分组中的值同样处理
+	MaxAttrs        int // 每条记录最多保留的属性数，不含 With、context 提取器和 xslog 附加（logger、seq 等）的属性
+}
+
+type sizeLimiter struct {
+	SizeLimits
+}
+
+func newSizeLimiter(cfg *SizeLimits) *sizeLimiter {
+	if cfg == nil || cfg.MaxMessageBytes <= 0 && cfg.MaxValueBytes <= 0 && cfg.MaxAttrs <= 0 {
+		return nil
+	}
+	return &sizeLimiter{*cfg}
+}
+
+// record 按限制截断记录，没有超出时原样返回。属性中的 LogValuer（如 Lazy）在这里求值一次，
+// 按求值结果判断大小，之后的输出不再重复求值
+func (l *sizeLimiter) record(r slog.Record) slog.Record {
+	if l == nil {
+		return r
+	}
+	msg, truncated := truncateString(r.Message, l.MaxMessageBytes)
+	changed := truncated
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if l.MaxAttrs > 0 && len(attrs) >= l.MaxAttrs {
+			truncated = true
+			return false
+		}
+		a2, c := l.attr(a)
+		truncated = truncated || c
+		changed = changed || c || a2.Value.Kind() != a.Value.Kind()
+		attrs = append(attrs, a2)
+		return true
+	})
+	if !changed && !truncated {
+		return r
+	}
+	r2 := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+	r2.AddAttrs(attrs...)
+	if truncated {
+		r2.AddAttrs(slog.Bool(TruncatedKey, true))
+	}
+	return r2
+}
+
+// attrs 截断 With 附加的属性值，它们属于所有后续记录，不附加 truncated 标记
+func (l *sizeLimiter) attrs(attrs []slog.Attr) []slog.Attr {
+	if l == nil {
+		return attrs
+	}
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i], _ = l.attr(a)
+	}
+	return out
+}
+
+func (l *sizeLimiter) attr(a slog.Attr) (slog.Attr, bool) {
+	if l.MaxValueBytes <= 0 {
+		return a, false
+	}
+	if a.Value.Kind() == slog.KindLogValuer && !isOwnValue(a.Value) {
+		a.Value = a.Value.Resolve()
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s, ok := truncateString(a.Value.String(), l.MaxValueBytes); ok {
+			return slog.String(a.Key, s), true
+		}
+	case slog.KindGroup:
+		if g, ok := mapGroup(a.Value, l.attr); ok {
+			return slog.Attr{Key: a.Key, Value: g}, true
+		}
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case []byte:
+			if len(v) > l.MaxValueBytes {
+				return slog.Any(a.Key, v[:l.MaxValueBytes]), true
+			}
+		case error:
+			// 过长时换成截断后的文本
+			if s, ok := truncateString(v.Error(), l.MaxValueBytes); ok {
+				return slog.String(a.Key, s), true
+			}
+		case fmt.Stringer:
+			if s, ok := truncateString(v.String(), l.MaxValueBytes); ok {
+				return slog.String(a.Key, s), true
+			}
+		}
+	}
+	return a, false
+}
+
+// truncateString 把 s 截到最多 max 字节，不切断多字节字符
+func truncateString(s string, max int) (string, bool) {
+	if max <= 0 || len(s) <= max {
+		return s, false
+	}
+	i := max
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i], true
+}
@@ -0,0 +1,41 @@
+package xslog
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSizeLimitsKeepLibraryAttrs(t *testing.T) {
+	obs := NewObserverSink(slog.LevelDebug)
+	ml, err := NewLogger(LogConfig{
+		IgnoreEnv: true,
+		Sequence:  true,
+		Handlers:  []slog.Handler{obs},
+		Limits:    &SizeLimits{MaxAttrs: 2, MaxValueBytes: 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ml.Close()
+
+	ml.Named("db").Info("one attr", "e", 1)
+	ml.Info("lazy", "l", Lazy(func() any { return strings.Repeat("x", 100) }))
+
+	rs := obs.Records()
+	if rs.Len() != 2 {
+		t.Fatalf("got %d records, want 2", rs.Len())
+	}
+	first := rs[0]
+	for _, key := range []string{"e", LoggerNameKey, SeqKey} {
+		if _, ok := first.Attr(key); !ok {
+			t.Errorf("record 1: missing %s", key)
+		}
+	}
+	if _, ok := first.Attr(TruncatedKey); ok {
+		t.Errorf("record 1: marked %s with a single attr", TruncatedKey)
+	}
+	if v, _ := rs[1].Attr("l"); v.String() != "xxxxxxxx" {
+		t.Errorf("record 2: l = %q, want it truncated to 8 bytes", v.String())
+	}
+}
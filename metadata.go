@@ -0,0 +1,128 @@
+package xslog
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LogConfig.ProcessInfo 附加的属性名
+const (
+	HostKey       = "host"
+	PIDKey        = "pid"
+	ExecutableKey = "exe"
+)
+
+// processAttrs 返回主机名、进程号和可执行文件名，只计算一次；取不到的值省略
+var processAttrs = sync.OnceValue(func() []slog.Attr {
+	var attrs []slog.Attr
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, slog.String(HostKey, host))
+	}
+	attrs = append(attrs, slog.Int(PIDKey, os.Getpid()))
+	if exe, err := os.Executable(); err == nil {
+		attrs = append(attrs, slog.String(ExecutableKey, filepath.Base(exe)))
+	}
+	return attrs
+})
+
+// SeqKey 是 LogConfig.Sequence 附加的序号属性名
+const SeqKey = "seq"
+
+// MonotonicKey 是 LogConfig.MonotonicTime 附加的属性名
+const MonotonicKey = "mono"
+
+// processStart 是进程启动（加载 xslog 包）的时间，带有单调时钟读数
+var processStart = time.Now()
+
+// monotonicSince 返回 t 距进程启动的时长；t 带有单调时钟读数时（time.Now 的返回值）按单调时钟计算，
+// 不受 NTP 校时和手动修改系统时间的影响
+func monotonicSince(t time.Time) time.Duration {
+	return t.Sub(processStart)
+}
+
+// GoroutineKey 是 LogConfig.GoroutineID 附加的属性名
+const GoroutineKey = "goroutine"
+
+// goroutineID 返回当前 goroutine 的编号。运行时不公开这个值，只能从 runtime.Stack 的
+// 第一行 "goroutine 123 [running]:" 中解析，每次调用约一微秒
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// LogConfig.BuildInfo 附加的属性名
+const (
+	VersionKey   = "version"
+	RevisionKey  = "revision"
+	GoVersionKey = "go_version"
+)
+
+// BuildInfoMode 决定是否以及如何记录 debug.ReadBuildInfo 中的版本信息
+type BuildInfoMode int
+
+const (
+	BuildInfoOff     BuildInfoMode = iota // 不记录，默认
+	BuildInfoRecords                      // 每条记录都带上版本信息
+	BuildInfoStartup                      // 创建 logger 时记录一条带版本信息的 Info 记录
+)
+
+// BuildInfoMessage 是 BuildInfoStartup 记录的消息
+const BuildInfoMessage = "build info"
+
+// buildAttrs 返回主模块版本（version）、VCS 修订号（revision，有未提交的改动时加 "-dirty"）和 Go 版本，
+// 只计算一次；没有构建信息或值为空时省略
+var buildAttrs = sync.OnceValue(func() []slog.Attr {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	var attrs []slog.Attr
+	if v := info.Main.Version; v != "" {
+		attrs = append(attrs, slog.String(VersionKey, v))
+	}
+	var revision string
+	var modified bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if revision != "" {
+		if modified {
+			revision += "-dirty"
+		}
+		attrs = append(attrs, slog.String(RevisionKey, revision))
+	}
+	return append(attrs, slog.String(GoVersionKey, info.GoVersion))
+})
+
+// staticAttrs 返回按配置附加到每条记录的属性
+func staticAttrs(config LogConfig) []slog.Attr {
+	var attrs []slog.Attr
+	for _, key := range sortedKeys(config.Attrs) {
+		attrs = append(attrs, slog.Any(key, config.Attrs[key]))
+	}
+	if config.ProcessInfo {
+		attrs = append(attrs, processAttrs()...)
+	}
+	if config.BuildInfo == BuildInfoRecords {
+		attrs = append(attrs, buildAttrs()...)
+	}
+	return attrs
+}
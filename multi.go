@@ -0,0 +1,206 @@
+package xslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+)
+
+// MultiHandler 把同一条记录分发给多个 handler，每个 handler 各自判断级别
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
+}
+
+// Enabled 只要有一个 handler 会记录该级别就返回 true
+func (m *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf [4]slog.Handler
+	return dispatch(ctx, r, m.targets(ctx, r.Level, false, buf[:0]), false)
+}
+
+// targets 把会处理 level 级别记录的 handler 追加到 dst。force 时（命中级别覆盖规则）
+// 控制台只看输出开关，日志文件所在的 forceHandler 自己区分哪些输出忽略级别，
+// LogConfig.Handlers 等其他 handler 仍按各自的 Enabled 过滤；
+// 在格式化记录之前调用，没有目标时调用方可以直接返回
+func (m *MultiHandler) targets(ctx context.Context, level slog.Level, force bool, dst []slog.Handler) []slog.Handler {
+	for _, h := range m.handlers {
+		if _, ok := h.(*auditSink); ok {
+			continue
+		}
+		switch {
+		case force && forced(h):
+			// 控制台只看输出开关，forceHandler 自己检查日志文件的开关
+			if s, ok := h.(*sinkHandler); ok && !s.on.Load() {
+				continue
+			}
+		case !h.Enabled(ctx, level):
+			continue
+		}
+		dst = append(dst, h)
+	}
+	return dst
+}
+
+// forced 报告命中级别覆盖规则时 h 是否跳过 Enabled：控制台（sinkHandler）和包含日志文件的 forceHandler
+func forced(h slog.Handler) bool {
+	switch h.(type) {
+	case *sinkHandler, forceHandler:
+		return true
+	}
+	return false
+}
+
+// forceHandler 由内部包含多个输出、需要自己区分级别覆盖的 handler 实现
+type forceHandler interface {
+	handleForce(ctx context.Context, r slog.Record) error
+}
+
+// dispatch 把记录交给 targets 中的每个 handler；多于一个时先统一求值 LogValuer，
+// 各个输出不再重复计算
+func dispatch(ctx context.Context, r slog.Record, targets []slog.Handler, force bool) error {
+	if len(targets) > 1 {
+		r = resolveRecord(r)
+	}
+	var errs []error
+	for _, h := range targets {
+		if err := handleRecord(ctx, h, r, force); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func handleRecord(ctx context.Context, h slog.Handler, r slog.Record, force bool) error {
+	if fh, ok := h.(forceHandler); ok && force {
+		return fh.handleForce(ctx, r)
+	}
+	return h.Handle(ctx, r)
+}
+
+// resolveRecord 返回属性中的 LogValuer 都已求值的记录，没有需要求值的属性时原样返回
+func resolveRecord(r slog.Record) slog.Record {
+	changed := false
+	r.Attrs(func(a slog.Attr) bool {
+		changed = needsResolve(a.Value)
+		return !changed
+	})
+	if !changed {
+		return r
+	}
+	r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		r2.AddAttrs(resolveAttr(a))
+		return true
+	})
+	return r2
+}
+
+// needsResolve 报告值或分组内是否有需要求值的 LogValuer，xslog 自己的特殊值保持原样
+func needsResolve(v slog.Value) bool {
+	switch v.Kind() {
+	case slog.KindLogValuer:
+		return !isOwnValue(v)
+	case slog.KindGroup:
+		for _, a := range v.Group() {
+			if needsResolve(a.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func resolveAttr(a slog.Attr) slog.Attr {
+	if !needsResolve(a.Value) {
+		return a
+	}
+	if a.Value.Kind() == slog.KindLogValuer {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() != slog.KindGroup {
+			return a
+		}
+	}
+	group := a.Value.Group()
+	attrs := make([]slog.Attr, len(group))
+	for i, ga := range group {
+		attrs[i] = resolveAttr(ga)
+	}
+	a.Value = slog.GroupValue(attrs...)
+	return a
+}
+
+func (m *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return m.withAttrs(attrs)
+}
+
+func (m *MultiHandler) WithGroup(name string) slog.Handler {
+	return m.withGroup(name)
+}
+
+// auditSink 返回审计输出，没有配置时为 nil
+func (m *MultiHandler) auditSink() *auditSink {
+	for _, h := range m.handlers {
+		if s, ok := h.(*auditSink); ok {
+			return s
+		}
+	}
+	return nil
+}
+
+func (m *MultiHandler) withAttrs(attrs []slog.Attr) *MultiHandler {
+	if len(attrs) == 0 {
+		return m
+	}
+	handlers := make([]slog.Handler, len(m.handlers))
+	for i, h := range m.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+func (m *MultiHandler) withGroup(name string) *MultiHandler {
+	if name == "" {
+		return m
+	}
+	handlers := make([]slog.Handler, len(m.handlers))
+	for i, h := range m.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+// sinkHandler 是 Logger 的一个输出，on 由 EnableConsole/EnableFile 控制，
+// 派生出的子 handler 共享同一个开关
+type sinkHandler struct {
+	on    *atomic.Bool
+	inner slog.Handler
+}
+
+func (s *sinkHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return s.on.Load() && s.inner.Enabled(ctx, level)
+}
+
+func (s *sinkHandler) Handle(ctx context.Context, r slog.Record) error {
+	return s.inner.Handle(ctx, r)
+}
+
+func (s *sinkHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sinkHandler{on: s.on, inner: s.inner.WithAttrs(attrs)}
+}
+
+func (s *sinkHandler) WithGroup(name string) slog.Handler {
+	return &sinkHandler{on: s.on, inner: s.inner.WithGroup(name)}
+}
@@ -0,0 +1,273 @@
+package xslog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObservedRecord 是 ObserverSink 记下的一条记录。Attrs 包括 With 附加的属性，
+// 分组已经嵌套好，LogValuer 已经求值，空 key 的分组（如 Err 的属性）展开到所在层级
+type ObservedRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   []slog.Attr
+}
+
+// Attr 返回 key 对应的属性值，key 可以用 "." 访问分组内的属性，如 "req.id"
+func (r ObservedRecord) Attr(key string) (slog.Value, bool) {
+	attrs := r.Attrs
+	for {
+		name, rest, nested := strings.Cut(key, ".")
+		var found *slog.Attr
+		for i := range attrs {
+			if attrs[i].Key == name {
+				found = &attrs[i]
+			}
+		}
+		if found == nil {
+			return slog.Value{}, false
+		}
+		if !nested {
+			return found.Value, true
+		}
+		if found.Value.Kind() != slog.KindGroup {
+			return slog.Value{}, false
+		}
+		attrs, key = found.Value.Group(), rest
+	}
+}
+
+// ObservedRecords 是一组记下的记录，Filter 方法返回满足条件的子集，可以链式调用：
+//
+//	errs := obs.Records().FilterLevel(slog.LevelError).FilterAttr("code", 429)
+//	if errs.Len() != 1 { ... }
+type ObservedRecords []ObservedRecord
+
+// Len 返回记录条数
+func (rs ObservedRecords) Len() int {
+	return len(rs)
+}
+
+// Filter 返回 fn 返回 true 的记录
+func (rs ObservedRecords) Filter(fn func(ObservedRecord) bool) ObservedRecords {
+	var out ObservedRecords
+	for _, r := range rs {
+		if fn(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// FilterLevel 返回级别恰好为 level 的记录
+func (rs ObservedRecords) FilterLevel(level slog.Level) ObservedRecords {
+	return rs.Filter(func(r ObservedRecord) bool { return r.Level == level })
+}
+
+// FilterMinLevel 返回级别不低于 level 的记录
+func (rs ObservedRecords) FilterMinLevel(level slog.Level) ObservedRecords {
+	return rs.Filter(func(r ObservedRecord) bool { return r.Level >= level })
+}
+
+// FilterMessage 返回消息恰好为 msg 的记录
+func (rs ObservedRecords) FilterMessage(msg string) ObservedRecords {
+	return rs.Filter(func(r ObservedRecord) bool { return r.Message == msg })
+}
+
+// FilterMessageContains 返回消息包含 sub 的记录
+func (rs ObservedRecords) FilterMessageContains(sub string) ObservedRecords {
+	return rs.Filter(func(r ObservedRecord) bool { return strings.Contains(r.Message, sub) })
+}
+
+// FilterAttrKey 返回带有 key 属性的记录，key 的写法与 ObservedRecord.Attr 相同
+func (rs ObservedRecords) FilterAttrKey(key string) ObservedRecords {
+	return rs.Filter(func(r ObservedRecord) bool {
+		_, ok := r.Attr(key)
+		return ok
+	})
+}
+
+// FilterAttr 返回 key 属性等于 value 的记录。数值按 slog 的类型比较，
+// 如 int 和 int64 视为相同；value 为字符串时也和属性值的字符串形式比较
+func (rs ObservedRecords) FilterAttr(key string, value any) ObservedRecords {
+	want := slog.AnyValue(value)
+	return rs.Filter(func(r ObservedRecord) bool { return r.attrEqual(key, want) })
+}
+
+// attrEqual 报告 key 属性是否等于 want，规则见 FilterAttr
+func (r ObservedRecord) attrEqual(key string, want slog.Value) bool {
+	v, ok := r.Attr(key)
+	if !ok {
+		return false
+	}
+	return v.Equal(want) || want.Kind() == slog.KindString && v.String() == want.String()
+}
+
+// ObserverSink 是把记录保存在内存中的 slog.Handler，用于测试中检查写过哪些日志。
+// 可以直接用 slog.New，也可以通过 LogConfig.Handlers 接到 Logger 上，
+// 这时看到的是经过脱敏、截断等处理之后的记录
+//
+//	obs := xslog.NewObserverSink(nil)
+//	logger := xslog.NewTestLogger(t, xslog.WithHandler(obs))
+type ObserverSink struct {
+	level  slog.Leveler
+	store  *observerStore
+	attrs  []slog.Attr // WithAttrs 附加的属性，已经包进当时打开的分组
+	groups []string
+}
+
+type observerStore struct {
+	mu      sync.Mutex
+	records []ObservedRecord
+	max     int // 大于 0 时只保留最近 max 条，records 作为环形缓冲使用
+	next    int // 环形缓冲写满后下一条要覆盖的位置
+	subs    map[chan ObservedRecord]struct{}
+}
+
+// subscribe 返回之后每条新记录的通道，接收方跟不上时丢弃记录而不是阻塞写日志的一方；
+// 用完后调用 cancel
+func (s *observerStore) subscribe(buffer int) (_ <-chan ObservedRecord, cancel func()) {
+	ch := make(chan ObservedRecord, buffer)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = map[chan ObservedRecord]struct{}{}
+	}
+	s.subs[ch] = struct{}{}
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, ch)
+	}
+}
+
+func (s *observerStore) add(rec ObservedRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- rec:
+		default:
+		}
+	}
+	if s.max <= 0 || len(s.records) < s.max {
+		s.records = append(s.records, rec)
+		return
+	}
+	s.records[s.next] = rec
+	s.next = (s.next + 1) % s.max
+}
+
+// snapshot 按写入顺序返回记录的副本，reset 为 true 时同时清空
+func (s *observerStore) snapshot(reset bool) ObservedRecords {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(ObservedRecords, 0, len(s.records))
+	out = append(append(out, s.records[s.next:]...), s.records[:s.next]...)
+	if reset {
+		s.records, s.next = nil, 0
+	}
+	return out
+}
+
+// NewObserverSink 返回记录 level 及以上级别的 ObserverSink，level 为 nil 时记录所有级别
+func NewObserverSink(level slog.Leveler) *ObserverSink {
+	if level == nil {
+		level = slog.Level(-1 << 31)
+	}
+	return &ObserverSink{level: level, store: &observerStore{}}
+}
+
+// Records 返回到目前为止记下的所有记录（包括派生出的子 handler 记下的）
+func (o *ObserverSink) Records() ObservedRecords {
+	return o.store.snapshot(false)
+}
+
+// TakeAll 返回并清空记下的记录
+func (o *ObserverSink) TakeAll() ObservedRecords {
+	return o.store.snapshot(true)
+}
+
+// Len 返回记下的记录条数
+func (o *ObserverSink) Len() int {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+	return len(o.store.records)
+}
+
+// Reset 清空记下的记录
+func (o *ObserverSink) Reset() {
+	o.store.snapshot(true)
+}
+
+func (o *ObserverSink) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= o.level.Level()
+}
+
+func (o *ObserverSink) Handle(ctx context.Context, r slog.Record) error {
+	own := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		own = append(own, a)
+		return true
+	})
+	attrs := append(append([]slog.Attr(nil), o.attrs...), nestAttrs(o.groups, own)...)
+	o.store.add(ObservedRecord{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: observedAttrs(attrs)})
+	return nil
+}
+
+func (o *ObserverSink) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return o
+	}
+	o2 := *o
+	o2.attrs = append(o.attrs[:len(o.attrs):len(o.attrs)], nestAttrs(o.groups, attrs)...)
+	return &o2
+}
+
+func (o *ObserverSink) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return o
+	}
+	o2 := *o
+	o2.groups = append(o.groups[:len(o.groups):len(o.groups)], name)
+	return &o2
+}
+
+// nestAttrs 把属性包进 groups 表示的分组，没有属性时分组也不保留
+func nestAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+// observedAttrs 对属性求值，去掉空属性，把空 key 的分组展开到当前层级
+func observedAttrs(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		if a.Value.Kind() == slog.KindGroup {
+			group := observedAttrs(a.Value.Group())
+			if a.Key == "" {
+				out = append(out, group...)
+				continue
+			}
+			if len(group) == 0 {
+				continue
+			}
+			a.Value = slog.GroupValue(group...)
+		}
+		out = append(out, a)
+	}
+	return out
+}
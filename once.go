@@ -0,0 +1,74 @@
+package xslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// onceKeys 记录 InfoOnce 等方法已经输出过的键
+type onceKeys struct {
+	mu   sync.Mutex
+	keys map[string]time.Time // 键到过期时间，零值表示永不过期
+}
+
+// first 报告 key 是否是第一次出现（或上次已过期），ttl 为 0 时在进程内只出现一次
+func (o *onceKeys) first(key string, ttl time.Duration, now time.Time) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if exp, ok := o.keys[key]; ok && (exp.IsZero() || now.Before(exp)) {
+		return false
+	}
+	if o.keys == nil {
+		o.keys = map[string]time.Time{}
+	}
+	var exp time.Time
+	if ttl > 0 {
+		exp = now.Add(ttl)
+	}
+	o.keys[key] = exp
+	return true
+}
+
+// once 在 level 会被记录且 key 第一次出现时返回 true；级别未启用时不占用 key，
+// 之后调低级别仍然可以看到这条记录
+func (ml *Logger) once(ctx context.Context, level slog.Level, key string, ttl time.Duration) bool {
+	return ml.enabled(ctx, level) && ml.onceKeys.first(key, ttl, ml.now())
+}
+
+// LogOnce 对同一个 key 只记录一次，ttl 大于 0 时过期后可以再次记录；
+// key 在父子 logger 之间共享，适合废弃警告、启动提示等在循环中调用的日志
+func (ml *Logger) LogOnce(ctx context.Context, level slog.Level, key string, ttl time.Duration, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ml.once(ctx, level, key, ttl) {
+		ml.log(ctx, level, msg, args...)
+	}
+}
+
+// InfoOnce 对同一个 key 在进程内只记录一次
+func (ml *Logger) InfoOnce(key, msg string, args ...any) {
+	if ml.once(context.Background(), slog.LevelInfo, key, 0) {
+		ml.log(context.Background(), slog.LevelInfo, msg, args...)
+	}
+}
+
+func (ml *Logger) WarnOnce(key, msg string, args ...any) {
+	if ml.once(context.Background(), slog.LevelWarn, key, 0) {
+		ml.log(context.Background(), slog.LevelWarn, msg, args...)
+	}
+}
+
+func (ml *Logger) ErrorOnce(key, msg string, args ...any) {
+	if ml.once(context.Background(), slog.LevelError, key, 0) {
+		ml.log(context.Background(), slog.LevelError, msg, args...)
+	}
+}
+
+func (ml *Logger) DebugOnce(key, msg string, args ...any) {
+	if ml.once(context.Background(), slog.LevelDebug, key, 0) {
+		ml.log(context.Background(), slog.LevelDebug, msg, args...)
+	}
+}
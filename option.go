@@ -0,0 +1,334 @@
+package xslog
+
+import (
+	"crypto/ed25519"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Option 配置 NewLoggerWithOptions 创建的 logger
+type Option func(*loggerOptions)
+
+type loggerOptions struct {
+	config     LogConfig
+	extractors []ContextExtractor
+	onError    func(sink string, err error)
+}
+
+// NewLoggerWithOptions 用函数式选项创建 logger，
+// 未指定的选项与 LogConfig 的零值含义相同
+//
+//	logger, err := xslog.NewLoggerWithOptions(
+//		xslog.WithConsole(slog.LevelInfo),
+//		xslog.WithFile("logs/app.log", slog.LevelDebug),
+//	)
+func NewLoggerWithOptions(opts ...Option) (*Logger, error) {
+	var o loggerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ml, err := NewLogger(o.config)
+	if err != nil {
+		return nil, err
+	}
+	ml.AddContextExtractor(o.extractors...)
+	if o.onError != nil {
+		ml.OnError(o.onError)
+	}
+	return ml, nil
+}
+
+// WithConsole 启用控制台输出并设置级别
+func WithConsole(level slog.Level) Option {
+	return func(o *loggerOptions) {
+		o.config.LogToConsole = true
+		o.config.LevelForConsole = level
+	}
+}
+
+// WithConsoleWriter 把控制台输出写到 w 而不是 os.Stdout，noColor 为 true 时不输出颜色
+func WithConsoleWriter(w io.Writer, noColor bool) Option {
+	return func(o *loggerOptions) {
+		o.config.ConsoleWriter = w
+		o.config.ConsoleNoColor = noColor
+	}
+}
+
+// WithConsoleFlattenGroups 让控制台把分组显示为 parent.child.key=value，见 LogConfig.ConsoleFlattenGroups
+func WithConsoleFlattenGroups() Option {
+	return func(o *loggerOptions) {
+		o.config.ConsoleFlattenGroups = true
+	}
+}
+
+// WithConsoleLevelLabels 设置控制台级别标签的写法和自定义标签，见 LogConfig.ConsoleLevelStyle
+func WithConsoleLevelLabels(style LevelStyle, labels map[slog.Level]string) Option {
+	return func(o *loggerOptions) {
+		o.config.ConsoleLevelStyle = style
+		o.config.ConsoleLevelLabels = labels
+	}
+}
+
+// WithTheme 设置控制台的配色，见 Theme
+func WithTheme(t Theme) Option {
+	return func(o *loggerOptions) {
+		o.config.ConsoleTheme = t
+	}
+}
+
+// WithConsoleLineColor 让控制台整行按级别着色，见 LogConfig.ConsoleLineColor
+func WithConsoleLineColor() Option {
+	return func(o *loggerOptions) {
+		o.config.ConsoleLineColor = true
+	}
+}
+
+// WithConsoleAttrOrder 设置控制台属性的顺序：sorted 为 true 时按键名排序，
+// pinned 中的属性总是排在最前面，见 LogConfig.ConsoleSortAttrs
+//
+//	xslog.WithConsoleAttrOrder(false, "request_id", "trace_id")
+func WithConsoleAttrOrder(sorted bool, pinned ...string) Option {
+	return func(o *loggerOptions) {
+		o.config.ConsoleSortAttrs = sorted
+		o.config.ConsolePinnedKeys = pinned
+	}
+}
+
+// WithHandler 添加额外的 slog.Handler，见 LogConfig.Handlers
+func WithHandler(handlers ...slog.Handler) Option {
+	return func(o *loggerOptions) {
+		o.config.Handlers = append(o.config.Handlers, handlers...)
+	}
+}
+
+// WithRingBuffer 在内存中保留最近 size 条记录，见 LogConfig.RingBuffer
+func WithRingBuffer(size int) Option {
+	return func(o *loggerOptions) {
+		o.config.RingBuffer = size
+	}
+}
+
+// WithClock 用 now 代替 time.Now 给记录打时间，见 LogConfig.Clock
+func WithClock(now func() time.Time) Option {
+	return func(o *loggerOptions) {
+		o.config.Clock = now
+	}
+}
+
+// WithErrorCauses 把 Err 记录的错误链展开为 error_causes 数组，见 LogConfig.ErrorCauses
+func WithErrorCauses() Option {
+	return func(o *loggerOptions) {
+		o.config.ErrorCauses = true
+	}
+}
+
+// WithSequence 为每条记录附加递增的序号，见 LogConfig.Sequence
+func WithSequence() Option {
+	return func(o *loggerOptions) {
+		o.config.Sequence = true
+	}
+}
+
+// WithMonotonicTime 为每条记录附加按单调时钟计算的距进程启动的时长，见 LogConfig.MonotonicTime
+func WithMonotonicTime() Option {
+	return func(o *loggerOptions) {
+		o.config.MonotonicTime = true
+	}
+}
+
+// WithGoroutineID 为每条记录附加 goroutine 编号，开销较大，只用于排查问题，见 LogConfig.GoroutineID
+func WithGoroutineID() Option {
+	return func(o *loggerOptions) {
+		o.config.GoroutineID = true
+	}
+}
+
+// WithStaticAttrs 为每条记录附加固定属性，多次调用时合并，见 LogConfig.Attrs
+//
+//	xslog.WithStaticAttrs(map[string]any{"env": "prod", "region": "eu-west-1"})
+func WithStaticAttrs(attrs map[string]any) Option {
+	return func(o *loggerOptions) {
+		if o.config.Attrs == nil {
+			o.config.Attrs = make(map[string]any, len(attrs))
+		}
+		for k, v := range attrs {
+			o.config.Attrs[k] = v
+		}
+	}
+}
+
+// WithProcessInfo 为每条记录附加主机名、进程号和可执行文件名，见 LogConfig.ProcessInfo
+func WithProcessInfo() Option {
+	return func(o *loggerOptions) {
+		o.config.ProcessInfo = true
+	}
+}
+
+// WithBuildInfo 记录版本信息，见 LogConfig.BuildInfo
+func WithBuildInfo(mode BuildInfoMode) Option {
+	return func(o *loggerOptions) {
+		o.config.BuildInfo = mode
+	}
+}
+
+// WithFile 启用文件输出并设置路径和级别
+func WithFile(path string, level slog.Level) Option {
+	return func(o *loggerOptions) {
+		o.config.LogToFile = true
+		o.config.LogFilePath = path
+		o.config.LevelForFile = level
+	}
+}
+
+// WithRotation 启用日志文件轮转，见 RotationConfig
+//
+//	xslog.WithRotation(xslog.RotationConfig{MaxSize: 100 << 20, MaxBackups: 7})
+func WithRotation(cfg RotationConfig) Option {
+	return func(o *loggerOptions) {
+		o.config.Rotation = &cfg
+	}
+}
+
+// WithStackTrace 为达到 level 的记录附加调用栈
+func WithStackTrace(level slog.Leveler) Option {
+	return func(o *loggerOptions) {
+		o.config.StackTraceLevel = level
+	}
+}
+
+// WithFileSync 设置日志文件的 fsync 策略
+func WithFileSync(policy SyncPolicy) Option {
+	return func(o *loggerOptions) {
+		o.config.FileSync = policy
+	}
+}
+
+// WithFileBuffer 为日志文件启用 size 字节的写缓冲，每隔 interval（0 为默认 1s）写到文件
+func WithFileBuffer(size int, interval time.Duration) Option {
+	return func(o *loggerOptions) {
+		o.config.FileBufferSize = size
+		o.config.FileFlushInterval = interval
+	}
+}
+
+// WithSampling 启用采样
+func WithSampling(cfg SamplingConfig) Option {
+	return func(o *loggerOptions) {
+		o.config.Sampling = &cfg
+	}
+}
+
+// WithRateLimit 启用按消息或属性值限流
+func WithRateLimit(cfg RateLimitConfig) Option {
+	return func(o *loggerOptions) {
+		o.config.RateLimit = &cfg
+	}
+}
+
+// WithRedact 设置需要脱敏的属性键名模式，见 LogConfig.Redact
+func WithRedact(patterns ...string) Option {
+	return func(o *loggerOptions) {
+		o.config.Redact = append(o.config.Redact, patterns...)
+	}
+}
+
+// WithScrub 增加按正则改写消息和属性值的规则，见 LogConfig.Scrub
+func WithScrub(rules ...ScrubRule) Option {
+	return func(o *loggerOptions) {
+		o.config.Scrub = append(o.config.Scrub, rules...)
+	}
+}
+
+// WithErrorStorm 启用错误风暴抑制
+func WithErrorStorm(cfg ErrorStormConfig) Option {
+	return func(o *loggerOptions) {
+		o.config.ErrorStorm = &cfg
+	}
+}
+
+// WithDedupe 合并 window 内连续重复的记录
+func WithDedupe(window time.Duration) Option {
+	return func(o *loggerOptions) {
+		o.config.Dedupe = window
+	}
+}
+
+// WithFileHashChain 为日志文件启用哈希链，见 LogConfig.FileHashChain
+func WithFileHashChain() Option {
+	return func(o *loggerOptions) {
+		o.config.FileHashChain = true
+	}
+}
+
+// WithFileSigning 用 key 为日志文件的每条记录签名，见 LogConfig.FileSigningKey
+func WithFileSigning(key ed25519.PrivateKey) Option {
+	return func(o *loggerOptions) {
+		o.config.FileSigningKey = key
+	}
+}
+
+// WithAudit 为 Logger.Audit 配置独立的输出，见 AuditConfig
+func WithAudit(cfg AuditConfig) Option {
+	return func(o *loggerOptions) {
+		o.config.Audit = &cfg
+	}
+}
+
+// WithSizeLimits 限制记录的大小，见 SizeLimits
+func WithSizeLimits(limits SizeLimits) Option {
+	return func(o *loggerOptions) {
+		o.config.Limits = &limits
+	}
+}
+
+// WithAsync 启用异步输出
+func WithAsync(cfg AsyncConfig) Option {
+	return func(o *loggerOptions) {
+		cfg.Enabled = true
+		o.config.Async = cfg
+	}
+}
+
+// WithoutEnv 不读取 LOG_LEVEL 等环境变量
+func WithoutEnv() Option {
+	return func(o *loggerOptions) {
+		o.config.IgnoreEnv = true
+	}
+}
+
+// WithInternalLog 设置 xslog 自身问题的输出，见 LogConfig.InternalLog
+func WithInternalLog(w io.Writer) Option {
+	return func(o *loggerOptions) {
+		o.config.InternalLog = w
+	}
+}
+
+// WithFallback 设置输出连续失败 after 次（0 为默认 3）后的备用输出
+func WithFallback(w io.Writer, after int) Option {
+	return func(o *loggerOptions) {
+		o.config.Fallback = w
+		o.config.FallbackAfter = after
+	}
+}
+
+// WithOnError 设置输出写入失败时的回调，见 Logger.OnError
+func WithOnError(fn func(sink string, err error)) Option {
+	return func(o *loggerOptions) {
+		o.onError = fn
+	}
+}
+
+// WithContextExtractor 注册 context 属性提取器
+func WithContextExtractor(fns ...ContextExtractor) Option {
+	return func(o *loggerOptions) {
+		o.extractors = append(o.extractors, fns...)
+	}
+}
+
+// WithOutput 增加一个与日志文件格式相同的 JSON 输出
+func WithOutput(out Output) Option {
+	return func(o *loggerOptions) {
+		o.config.Outputs = append(o.config.Outputs, out)
+	}
+}
@@ -0,0 +1,183 @@
+package xslog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Output 是额外的日志输出，默认与日志文件使用同一种 JSON 格式；
+// 每条记录对每种格式只编码一次，编码结果写给所有使用这种格式的输出。
+// Writer 由调用方负责关闭，Logger.Close 不会关闭它
+type Output struct {
+	Name   string       // 输出名称，用于 OnError 报告，默认为 "output"
+	Writer io.Writer    // 每次 Write 收到一条完整的记录（以换行结尾）
+	Level  slog.Leveler // 为 nil 时为 Info
+
+	// DeadLetter 不为空时，写入 Writer 失败的记录追加到这个本地文件，
+	// 之后可以用 Logger.ReplayDeadLetters 重新发送
+	DeadLetter string
+
+	PII    PIIPolicy // 如何处理 PII 标记的属性，默认 PIIRedact
+	Format Format    // 编码格式，默认为 JSON，见 FormatECS 等
+}
+
+// jsonDest 是共享编码结果的一个输出
+type jsonDest struct {
+	name   string
+	on     *atomic.Bool // 为 nil 时总是启用
+	level  slog.Leveler
+	w      io.Writer
+	pii    PIIPolicy
+	format Format // 为 nil 时为默认的 JSON 格式
+	enc    int    // 使用的编码器在 jsonSink.encs 中的下标
+
+	// overridable 为 true 时命中 SetLevelFor 规则的记录不受 level 限制，
+	// 只有日志文件如此，Outputs 总是按自己的 level 过滤
+	overridable bool
+}
+
+func (d *jsonDest) enabled(level slog.Level, force bool) bool {
+	if d.on != nil && !d.on.Load() {
+		return false
+	}
+	return force && d.overridable || level >= d.level.Level()
+}
+
+// jsonSink 把记录编码一次，再把同一份字节写给每个启用的输出；
+// 格式或 PIIPolicy 不同的输出各自编码一次
+type jsonSink struct {
+	dests   []*jsonDest
+	encs    []slog.Handler // 写入 capture 的编码器，每种格式和 PIIPolicy 的组合一个
+	capture *captureWriter
+	report  *errorReporter
+}
+
+// captureWriter 暂存 enc 写出的一条记录
+type captureWriter struct {
+	mu  sync.Mutex // 从编码到写完所有输出期间持有
+	buf []byte
+}
+
+func (c *captureWriter) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+	return len(p), nil
+}
+
+// newJSONSink 为 dests 创建编码器，格式和 PIIPolicy 都相同的 dest 共用一个
+func newJSONSink(dests []*jsonDest, report *errorReporter) *jsonSink {
+	s := &jsonSink{dests: dests, capture: &captureWriter{}, report: report}
+	type encKey struct {
+		format Format
+		pii    PIIPolicy
+	}
+	index := map[encKey]int{}
+	for _, d := range dests {
+		k := encKey{d.format, d.pii}
+		if k.format == nil {
+			k.format = jsonFormat{}
+		}
+		n, ok := index[k]
+		if !ok {
+			n = len(s.encs)
+			index[k] = n
+			s.encs = append(s.encs, &piiHandler{policy: d.pii, inner: k.format.encoder(s.capture)})
+		}
+		d.enc = n
+	}
+	return s
+}
+
+func (s *jsonSink) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, d := range s.dests {
+		if d.enabled(level, false) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *jsonSink) Handle(ctx context.Context, r slog.Record) error {
+	return s.handle(ctx, r, false)
+}
+
+func (s *jsonSink) handleForce(ctx context.Context, r slog.Record) error {
+	return s.handle(ctx, r, true)
+}
+
+func (s *jsonSink) handle(ctx context.Context, r slog.Record, force bool) error {
+	var buf [4]*jsonDest
+	dests := buf[:0]
+	for _, d := range s.dests {
+		if d.enabled(r.Level, force) {
+			dests = append(dests, d)
+		}
+	}
+	if len(dests) == 0 {
+		return nil
+	}
+
+	var ebuf [4]error
+	var sbuf [4]time.Time
+	errs := append(ebuf[:0], make([]error, len(dests))...)
+	starts := append(sbuf[:0], make([]time.Time, len(dests))...)
+	s.write(ctx, r, dests, errs, starts)
+	// 在释放锁之后回调，回调中写其他 logger 时不会死锁
+	for i, err := range errs {
+		s.report.result(dests[i].name, r.Level, starts[i], err)
+	}
+	return errors.Join(errs...)
+}
+
+// write 对每个用到的编码器编码一次，把结果写给对应的 dest，错误和开始写入的时间存入与 dests 一一对应的 errs、starts
+func (s *jsonSink) write(ctx context.Context, r slog.Record, dests []*jsonDest, errs []error, starts []time.Time) {
+	s.capture.mu.Lock()
+	defer s.capture.mu.Unlock()
+	for n, enc := range s.encs {
+		encoded, encErr := false, error(nil)
+		for i, d := range dests {
+			if d.enc != n {
+				continue
+			}
+			if !encoded {
+				s.capture.buf = s.capture.buf[:0]
+				encErr = enc.Handle(ctx, r)
+				encoded = true
+			}
+			if encErr != nil {
+				errs[i] = encErr
+				continue
+			}
+			// 编码器可以跳过与格式无关的记录，如访问日志中的普通日志
+			if len(s.capture.buf) == 0 {
+				continue
+			}
+			starts[i] = s.report.start()
+			_, errs[i] = d.w.Write(s.capture.buf)
+		}
+	}
+	if cap(s.capture.buf) > maxPooledBuffer {
+		s.capture.buf = nil
+	}
+}
+
+func (s *jsonSink) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return s.derive(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+func (s *jsonSink) WithGroup(name string) slog.Handler {
+	return s.derive(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+func (s *jsonSink) derive(fn func(slog.Handler) slog.Handler) *jsonSink {
+	s2 := *s
+	s2.encs = make([]slog.Handler, len(s.encs))
+	for i, enc := range s.encs {
+		s2.encs[i] = fn(enc)
+	}
+	return &s2
+}
@@ -0,0 +1,119 @@
+package xslog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+)
+
+// RedactedPII 是 PIIRedact 策略下显示的值
+const RedactedPII = "[PII]"
+
+// PIIPolicy 决定一个输出如何处理用 PII 标记的属性
+type PIIPolicy int
+
+const (
+	PIIRedact PIIPolicy = iota // 替换为 "[PII]"，默认
+	PIIShow                    // 原样输出，如开发环境的控制台
+	PIIHash                    // 输出值的 SHA-256 摘要前缀，可以关联同一用户但看不到原值
+	PIIDrop                    // 整个属性不输出
+)
+
+// PII 把属性标记为个人身份信息，各个输出按自己的 PIIPolicy 处理：
+// 控制台由 LogConfig.PIIConsole 决定，日志文件由 PIIFile 决定，Output 由 Output.PII 决定
+//
+//	logger.Info("signup", xslog.PII("email", email))
+func PII(key string, value any) slog.Attr {
+	return slog.Any(key, piiValue{v: value})
+}
+
+// piiValue 不实现 LogValuer，避免在输出识别它之前被求值；
+// 交给不认识它的 handler 时显示为 "[PII]"
+type piiValue struct {
+	v any
+}
+
+func (p piiValue) String() string { return RedactedPII }
+
+func (p piiValue) MarshalJSON() ([]byte, error) { return []byte(`"` + RedactedPII + `"`), nil }
+
+// apply 按策略返回实际输出的值，PIIDrop 时 ok 为 false
+func (p piiValue) apply(policy PIIPolicy) (v slog.Value, ok bool) {
+	switch policy {
+	case PIIShow:
+		return slog.AnyValue(p.v).Resolve(), true
+	case PIIHash:
+		sum := sha256.Sum256([]byte(fmt.Sprint(p.v)))
+		return slog.StringValue("sha256:" + hex.EncodeToString(sum[:8])), true
+	case PIIDrop:
+		return slog.Value{}, false
+	}
+	return slog.StringValue(RedactedPII), true
+}
+
+// piiHandler 在交给 inner 之前按 policy 改写 PII 属性。
+// 不用 ReplaceAttr 实现：slog 在 WithAttrs 中遇到 ReplaceAttr 返回的空属性时会输出错误的 JSON
+type piiHandler struct {
+	policy PIIPolicy
+	inner  slog.Handler
+}
+
+func (h *piiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *piiHandler) Handle(ctx context.Context, r slog.Record) error {
+	changed := false
+	r.Attrs(func(a slog.Attr) bool {
+		_, changed = piiAttr(a, h.policy)
+		return !changed
+	})
+	if !changed {
+		return h.inner.Handle(ctx, r)
+	}
+	r2 := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		a, _ = piiAttr(a, h.policy)
+		r2.AddAttrs(a)
+		return true
+	})
+	return h.inner.Handle(ctx, r2)
+}
+
+func (h *piiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	// 丢弃的属性不能以空属性传下去，slog 预格式化时仍会为它写分隔符
+	own := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a, _ = piiAttr(a, h.policy); !a.Equal(slog.Attr{}) {
+			own = append(own, a)
+		}
+	}
+	return &piiHandler{policy: h.policy, inner: h.inner.WithAttrs(own)}
+}
+
+func (h *piiHandler) WithGroup(name string) slog.Handler {
+	return &piiHandler{policy: h.policy, inner: h.inner.WithGroup(name)}
+}
+
+// piiAttr 按 policy 改写属性及其分组中的 PII 值
+func piiAttr(a slog.Attr, policy PIIPolicy) (slog.Attr, bool) {
+	switch a.Value.Kind() {
+	case slog.KindAny:
+		if p, ok := a.Value.Any().(piiValue); ok {
+			v, ok := p.apply(policy)
+			if !ok {
+				return slog.Attr{}, true
+			}
+			return slog.Attr{Key: a.Key, Value: v}, true
+		}
+	case slog.KindGroup:
+		if g, changed := mapGroup(a.Value, func(ga slog.Attr) (slog.Attr, bool) {
+			return piiAttr(ga, policy)
+		}); changed {
+			return slog.Attr{Key: a.Key, Value: g}, true
+		}
+	}
+	return a, false
+}
@@ -0,0 +1,81 @@
+package xslog
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Query 是对日志记录的过滤条件，零值的字段不参与过滤
+//
+//	// 最近一小时的错误
+//	entries, err := xslog.QueryLogFiles("logs/app.log", xslog.Query{
+//		Since:    time.Now().Add(-time.Hour),
+//		MinLevel: slog.LevelError,
+//	})
+type Query struct {
+	Since    time.Time      // 只包括不早于 Since 的记录
+	Until    time.Time      // 只包括早于 Until 的记录
+	MinLevel slog.Leveler   // 只包括不低于这个级别的记录
+	Attrs    map[string]any // 属性相等条件，键的写法和比较规则与 ObservedRecords.FilterAttr 相同
+	Message  string         // 消息中包含的子串
+	Limit    int            // 大于 0 时只保留最后 Limit 条匹配的记录
+}
+
+// Match 报告 r 是否满足所有条件（不考虑 Limit）
+func (q Query) Match(r ObservedRecord) bool {
+	if !q.Since.IsZero() && r.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !r.Time.Before(q.Until) {
+		return false
+	}
+	if q.MinLevel != nil && r.Level < q.MinLevel.Level() {
+		return false
+	}
+	if q.Message != "" && !strings.Contains(r.Message, q.Message) {
+		return false
+	}
+	for key, value := range q.Attrs {
+		if !r.attrEqual(key, slog.AnyValue(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query 返回满足 q 的记录
+func (rs ObservedRecords) Query(q Query) ObservedRecords {
+	out := rs.Filter(q.Match)
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+// Query 读完剩下的所有记录，返回满足 q 的记录；Limit 大于 0 时只在内存中保留最后 Limit 条
+func (rd *Reader) Query(q Query) ([]Entry, error) {
+	var out []Entry
+	for rd.Next() {
+		e := rd.Entry()
+		if !q.Match(e.ObservedRecord) {
+			continue
+		}
+		if q.Limit > 0 && len(out) == q.Limit {
+			copy(out, out[1:])
+			out = out[:len(out)-1]
+		}
+		out = append(out, e)
+	}
+	return out, rd.Err()
+}
+
+// QueryLogFiles 在 path 及其轮转出的文件（见 OpenLogFiles）中查找满足 q 的记录
+func QueryLogFiles(path string, q Query) ([]Entry, error) {
+	rd, err := OpenLogFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	return rd.Query(q)
+}
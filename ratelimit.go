@@ -0,0 +1,193 @@
+package xslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SuppressedKey 是限流或错误风暴抑制之后放行的记录上附加的属性名，值为此前被丢弃的同类记录数，
+// 两者同时有丢弃时合并为一个
+const SuppressedKey = "suppressed"
+
+// 限流器最多跟踪的键数，超过时清理已经回满的桶
+const maxRateLimitKeys = 10000
+
+// RateLimitConfig 配置按消息（或某个属性的值）限流，使用令牌桶：
+// 每个键每 Per 最多记录 Limit 条，可以瞬时用完。被丢弃的条数附加在该键下一条放行的记录上（suppressed 属性）；
+// 桶回满一个令牌时仍没有放行的记录，或者调用 Flush、Close 时，输出最后一条被丢弃的记录作为汇总
+//
+//	RateLimit: &xslog.RateLimitConfig{Limit: 5, Per: time.Minute} // 每种消息每分钟最多 5 条
+type RateLimitConfig struct {
+	Limit int
+	Per   time.Duration
+	Key   string // 为空时按消息限流，否则按该顶层属性的值，如 "error"
+}
+
+type rateLimiter struct {
+	cfg RateLimitConfig
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+
+	// 最后一条被丢弃的记录及其 logger，用于输出汇总
+	dropped slog.Record
+	from    *Logger
+	ctx     context.Context
+	force   bool
+	timer   *time.Timer
+	gen     uint64 // 每次报告丢弃数后加一，过期的定时器据此放弃
+}
+
+// rateSummary 是一个键被丢弃的条数的汇总，在 l.mu 之外输出
+type rateSummary struct {
+	from       *Logger
+	ctx        context.Context
+	r          slog.Record
+	force      bool
+	suppressed int
+}
+
+func newRateLimiter(cfg *RateLimitConfig) *rateLimiter {
+	if cfg == nil || cfg.Limit <= 0 || cfg.Per <= 0 {
+		return nil
+	}
+	return &rateLimiter{cfg: *cfg, buckets: map[string]*tokenBucket{}}
+}
+
+// allow 报告 ml 的记录是否放行；放行时返回此前被丢弃的同类记录数，
+// force 表示记录命中了级别覆盖规则，汇总按同样的方式选择输出
+func (l *rateLimiter) allow(ctx context.Context, ml *Logger, r slog.Record, force bool) (keep bool, suppressed int) {
+	if l == nil {
+		return true, 0
+	}
+	key, ok := l.key(r)
+	if !ok {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[key]
+	if b == nil {
+		if len(l.buckets) >= maxRateLimitKeys {
+			l.evict(r.Time)
+		}
+		b = &tokenBucket{tokens: float64(l.cfg.Limit), last: r.Time}
+		l.buckets[key] = b
+	}
+	l.refill(b, r.Time)
+	if b.tokens < 1 {
+		b.suppressed++
+		b.dropped, b.from, b.ctx, b.force = r.Clone(), ml, ctx, force
+		if b.timer == nil {
+			wait := time.Duration((1 - b.tokens) * float64(l.cfg.Per) / float64(l.cfg.Limit))
+			gen := b.gen
+			b.timer = time.AfterFunc(wait, func() { l.expire(b, gen) })
+		}
+		return false, 0
+	}
+	b.tokens--
+	return true, l.takeLocked(b).suppressed
+}
+
+// expire 在桶回满一个令牌时输出尚未报告的丢弃数，gen 不同说明这些丢弃已经报告过
+func (l *rateLimiter) expire(b *tokenBucket, gen uint64) {
+	l.mu.Lock()
+	if b.gen != gen || b.suppressed == 0 {
+		l.mu.Unlock()
+		return
+	}
+	s := l.takeLocked(b)
+	l.mu.Unlock()
+	s.emit()
+}
+
+// flush 输出所有尚未报告的丢弃数
+func (l *rateLimiter) flush() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	var pending []rateSummary
+	for _, b := range l.buckets {
+		if b.suppressed > 0 {
+			pending = append(pending, l.takeLocked(b))
+		}
+	}
+	l.mu.Unlock()
+	for _, s := range pending {
+		s.emit()
+	}
+}
+
+// takeLocked 取出 b 尚未报告的丢弃数并清零，调用方持有 l.mu
+func (l *rateLimiter) takeLocked(b *tokenBucket) rateSummary {
+	s := rateSummary{b.from, b.ctx, b.dropped, b.force, b.suppressed}
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.gen++
+	b.suppressed = 0
+	b.dropped, b.from, b.ctx = slog.Record{}, nil, nil
+	return s
+}
+
+// emit 输出带 suppressed 属性的汇总，即最后一条被丢弃的记录
+func (s rateSummary) emit() {
+	if s.suppressed == 0 {
+		return
+	}
+	var buf [4]slog.Handler
+	targets := s.from.handler.targets(s.ctx, s.r.Level, s.force, buf[:0])
+	if len(targets) == 0 {
+		return
+	}
+	_ = s.from.write(s.ctx, s.r, targets, s.force, s.suppressed, 0)
+}
+
+func (l *rateLimiter) key(r slog.Record) (string, bool) {
+	if l.cfg.Key == "" {
+		return r.Message, true
+	}
+	var key string
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == l.cfg.Key {
+			key, found = a.Value.Resolve().String(), true
+			return false
+		}
+		return true
+	})
+	return key, found
+}
+
+func (l *rateLimiter) refill(b *tokenBucket, now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.last = now
+	b.tokens += float64(l.cfg.Limit) * float64(elapsed) / float64(l.cfg.Per)
+	if b.tokens > float64(l.cfg.Limit) {
+		b.tokens = float64(l.cfg.Limit)
+	}
+}
+
+// evict 删除已经回满且没有待报告丢弃数的桶，它们与新建的桶没有区别
+func (l *rateLimiter) evict(now time.Time) {
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		if b.suppressed == 0 && b.tokens >= float64(l.cfg.Limit) {
+			delete(l.buckets, key)
+		}
+	}
+}
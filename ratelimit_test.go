@@ -0,0 +1,51 @@
+package xslog
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestRateLimitSummary(t *testing.T) {
+	obs := NewObserverSink(slog.LevelDebug)
+	ml, err := NewLogger(LogConfig{
+		IgnoreEnv: true,
+		Handlers:  []slog.Handler{obs},
+		RateLimit: &RateLimitConfig{Limit: 1, Per: 50 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ml.Close()
+
+	// 桶回满时输出汇总，不等同一条消息再次出现
+	for i := 0; i < 3; i++ {
+		ml.Info("busy", "i", i)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for obs.Records().Len() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	rs := obs.Records()
+	if rs.Len() != 2 {
+		t.Fatalf("got %d records after refill, want 2", rs.Len())
+	}
+	if v, _ := rs[1].Attr(SuppressedKey); v.Int64() != 2 {
+		t.Errorf("refill summary: suppressed = %v, want 2", v)
+	}
+	if v, _ := rs[1].Attr("i"); v.Int64() != 2 {
+		t.Errorf("refill summary: i = %v, want the last dropped record", v)
+	}
+
+	// Flush 输出尚未报告的丢弃数
+	ml.Info("flushed")
+	ml.Info("flushed")
+	ml.Flush()
+	rs = obs.Records()
+	if rs.Len() != 4 {
+		t.Fatalf("got %d records after Flush, want 4", rs.Len())
+	}
+	if v, _ := rs[3].Attr(SuppressedKey); v.Int64() != 1 {
+		t.Errorf("flush summary: suppressed = %v, want 1", v)
+	}
+}
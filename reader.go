@@ -0,0 +1,200 @@
+package xslog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Entry 是 Reader 从日志文件读出的一条记录
+type Entry struct {
+	ObservedRecord
+	File string // 所在文件，NewReader 读取时为空
+	Line int    // 在文件中的行号，从 1 开始
+}
+
+// Reader 逐条读取 xslog 写出的 JSON 日志，gzip 压缩的内容自动解压，不是记录的行（如 panic 输出）被跳过。
+// 用法与 bufio.Scanner 相同：
+//
+//	rd, err := xslog.OpenLogFiles("logs/app.log")
+//	if err != nil { ... }
+//	defer rd.Close()
+//	for rd.Next() {
+//		e := rd.Entry()
+//		...
+//	}
+//	if err := rd.Err(); err != nil { ... }
+type Reader struct {
+	paths   []string // 还没有打开的文件
+	file    string
+	closer  io.Closer
+	br      *bufio.Reader
+	line    int
+	entry   Entry
+	skipped int
+	err     error
+}
+
+// NewReader 返回从 r 读取记录的 Reader，r 可以是 gzip 压缩的
+func NewReader(r io.Reader) *Reader {
+	rd := &Reader{}
+	rd.err = rd.reset("", r, nil)
+	return rd
+}
+
+// OpenLogFiles 返回依次读取 path 及其轮转出的文件的 Reader。与 path 同目录、
+// 以 path 的文件名开头的文件（如 app.log.1、app.log.2.gz、app.log-20240102.gz）
+// 都视为轮转出的文件，按修改时间从旧到新读取，path 本身最后读取
+func OpenLogFiles(path string) (*Reader, error) {
+	rotated, err := filepath.Glob(globEscape(path) + "?*")
+	if err != nil {
+		return nil, err
+	}
+	type file struct {
+		path string
+		mod  int64
+	}
+	var files []file
+	for _, p := range rotated {
+		fi, err := os.Stat(p)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, file{p, fi.ModTime().UnixNano()})
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].mod < files[j].mod })
+	paths := make([]string, 0, len(files)+1)
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+	if _, err := os.Stat(path); err == nil || len(paths) == 0 {
+		paths = append(paths, path)
+	}
+	return OpenReader(paths...)
+}
+
+// OpenReader 返回按顺序读取 paths 中各个文件的 Reader，第一个文件打不开时返回错误
+func OpenReader(paths ...string) (*Reader, error) {
+	rd := &Reader{paths: paths}
+	if err := rd.openNext(); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// globEscape 转义 path 中的通配符
+func globEscape(path string) string {
+	var buf bytes.Buffer
+	for _, c := range path {
+		switch c {
+		case '*', '?', '[', '\\':
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
+
+func (rd *Reader) openNext() error {
+	path := rd.paths[0]
+	rd.paths = rd.paths[1:]
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return rd.reset(path, f, f)
+}
+
+// reset 开始读取 r，以 gzip 魔数开头时解压
+func (rd *Reader) reset(path string, r io.Reader, closer io.Closer) error {
+	rd.file, rd.closer, rd.line = path, closer, 0
+	rd.br = bufio.NewReaderSize(r, 64<<10)
+	if magic, _ := rd.br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(rd.br)
+		if err != nil {
+			return err
+		}
+		rd.br = bufio.NewReaderSize(zr, 64<<10)
+	}
+	return nil
+}
+
+// Next 读取下一条记录，没有更多记录或出错时返回 false
+func (rd *Reader) Next() bool {
+	for rd.err == nil {
+		line, err := rd.br.ReadBytes('\n')
+		if len(line) > 0 {
+			rd.line++
+			if rd.parse(line) {
+				return true
+			}
+		}
+		if err == nil {
+			continue
+		}
+		if err != io.EOF {
+			rd.err = err
+			break
+		}
+		if len(rd.paths) == 0 {
+			break
+		}
+		rd.closeFile()
+		rd.err = rd.openNext()
+	}
+	return false
+}
+
+func (rd *Reader) parse(line []byte) bool {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return false
+	}
+	r, err := UnmarshalRecord(line)
+	if err != nil {
+		rd.skipped++
+		return false
+	}
+	rec := ObservedRecord{Time: r.Time, Level: r.Level, Message: r.Message}
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attrs = append(rec.Attrs, a)
+		return true
+	})
+	rd.entry = Entry{ObservedRecord: rec, File: rd.file, Line: rd.line}
+	return true
+}
+
+// Entry 返回 Next 读到的记录
+func (rd *Reader) Entry() Entry {
+	return rd.entry
+}
+
+// Skipped 返回到目前为止跳过的非记录行数
+func (rd *Reader) Skipped() int {
+	return rd.skipped
+}
+
+// Err 返回读取中遇到的第一个错误，正常读完时为 nil
+func (rd *Reader) Err() error {
+	return rd.err
+}
+
+// Close 关闭正在读取的文件
+func (rd *Reader) Close() error {
+	rd.paths = nil
+	return rd.closeFile()
+}
+
+func (rd *Reader) closeFile() error {
+	if rd.closer == nil {
+		return nil
+	}
+	err := rd.closer.Close()
+	rd.closer = nil
+	return err
+}
@@ -0,0 +1,164 @@
+package xslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// MarshalRecord 把记录编码为日志文件使用的 JSON 格式（一个 JSON 对象，不带换行），
+// PII 标记的属性按 PIIRedact 输出
+func MarshalRecord(r slog.Record) ([]byte, error) {
+	var buf bytes.Buffer
+	h := &piiHandler{inner: jsonFormat{}.encoder(&buf)}
+	if err := h.Handle(context.Background(), r); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalRecord 解析日志文件中的一条 JSON 记录，time、level、msg 还原为记录的对应字段，
+// 其余字段按原来的顺序还原为属性：对象为分组，整数为 int64，其他数字为 float64，数组为 []any。
+// 时间、时长等类型在 JSON 中已经是字符串或数字，不会还原为原来的类型；调用位置无法还原，PC 为 0。
+// 文件完整性字段 prev_hash 和 sig 会被去掉，记录可以重新写入其他带哈希链或签名的文件
+func UnmarshalRecord(data []byte) (slog.Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return slog.Record{}, fmt.Errorf("unmarshal record: %w", err)
+	} else if tok != json.Delim('{') {
+		return slog.Record{}, errors.New("unmarshal record: expected a JSON object")
+	}
+	var (
+		r     slog.Record
+		attrs []slog.Attr
+	)
+	for dec.More() {
+		key, v, err := decodeMember(dec)
+		if err != nil {
+			return slog.Record{}, fmt.Errorf("unmarshal record: %w", err)
+		}
+		switch key {
+		case slog.TimeKey:
+			s, ok := v.Any().(string)
+			if !ok {
+				return slog.Record{}, fmt.Errorf("unmarshal record: %s: expected a string", key)
+			}
+			if r.Time, err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return slog.Record{}, fmt.Errorf("unmarshal record: %s: %w", key, err)
+			}
+		case slog.LevelKey:
+			s, ok := v.Any().(string)
+			if !ok {
+				return slog.Record{}, fmt.Errorf("unmarshal record: %s: expected a string", key)
+			}
+			if r.Level, err = ParseLevel(s); err != nil {
+				return slog.Record{}, fmt.Errorf("unmarshal record: %s: %w", key, err)
+			}
+		case slog.MessageKey:
+			r.Message = v.String()
+		case PrevHashKey, SignatureKey:
+		default:
+			attrs = append(attrs, slog.Attr{Key: key, Value: v})
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return slog.Record{}, fmt.Errorf("unmarshal record: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return slog.Record{}, errors.New("unmarshal record: trailing data after JSON object")
+	}
+	out := slog.NewRecord(r.Time, r.Level, r.Message, 0)
+	out.AddAttrs(attrs...)
+	return out, nil
+}
+
+// decodeMember 读取对象中的一个键值对
+func decodeMember(dec *json.Decoder) (string, slog.Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", slog.Value{}, err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", slog.Value{}, fmt.Errorf("expected an object key, got %v", tok)
+	}
+	v, err := decodeValue(dec)
+	return key, v, err
+}
+
+// decodeValue 读取一个 JSON 值，对象按字段顺序还原为分组
+func decodeValue(dec *json.Decoder) (slog.Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			var attrs []slog.Attr
+			for dec.More() {
+				key, v, err := decodeMember(dec)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				attrs = append(attrs, slog.Attr{Key: key, Value: v})
+			}
+			_, err := dec.Token()
+			return slog.GroupValue(attrs...), err
+		case '[':
+			list := []any{}
+			for dec.More() {
+				v, err := decodeValue(dec)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				list = append(list, jsonAny(v))
+			}
+			_, err := dec.Token()
+			return slog.AnyValue(list), err
+		}
+		return slog.Value{}, fmt.Errorf("unexpected %v", t)
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return slog.Int64Value(n), nil
+		}
+		f, err := t.Float64()
+		return slog.Float64Value(f), err
+	case string:
+		return slog.StringValue(t), nil
+	case bool:
+		return slog.BoolValue(t), nil
+	}
+	return slog.AnyValue(nil), nil
+}
+
+// jsonAny 把数组元素还原为 Go 的值，其中的对象为 map[string]any
+func jsonAny(v slog.Value) any {
+	if v.Kind() != slog.KindGroup {
+		return v.Any()
+	}
+	m := make(map[string]any, len(v.Group()))
+	for _, a := range v.Group() {
+		m[a.Key] = jsonAny(a.Value)
+	}
+	return m
+}
+
+// Replay 把之前记下的记录（如 UnmarshalRecord 的结果）重新交给 logger 的输出，
+// 保留记录原来的时间，不使用 LogConfig.Clock；级别、采样、脱敏等处理与普通记录相同
+func (ml *Logger) Replay(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !ml.enabled(ctx, r.Level) {
+		return nil
+	}
+	return ml.handle(ctx, r)
+}
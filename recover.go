@@ -0,0 +1,50 @@
+package xslog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// PanicKey 是 panic 值在日志记录中的属性名
+const PanicKey = "panic"
+
+// RecoverAndLog 用于 defer：捕获 panic，以 Error 级别记录 panic 值和堆栈后重新 panic，
+//
+//	defer xslog.RecoverAndLog(logger)
+func RecoverAndLog(logger *Logger) {
+	if v := recover(); v != nil {
+		logger.log(context.Background(), slog.LevelError, "panic recovered",
+			slog.Any(PanicKey, v), slog.Any(StackKey, captureStack(1)))
+		_ = logger.Flush()
+		panic(v)
+	}
+}
+
+// RecoverHandler 返回一个 HTTP 中间件：捕获 next 中的 panic，
+// 记录 panic 值、堆栈和请求信息后返回 500
+func RecoverHandler(logger *Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			// http.ErrAbortHandler 用于主动中断响应，按 net/http 的约定继续向上抛出
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			logger.log(r.Context(), slog.LevelError, "panic recovered",
+				slog.Any(PanicKey, v),
+				slog.Group("request",
+					slog.String("method", r.Method),
+					slog.String("url", r.URL.String()),
+					slog.String("remote_addr", r.RemoteAddr),
+				),
+				slog.Any(StackKey, captureStack(1)),
+			)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,194 @@
+package xslog
+
+import (
+	"log/slog"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue 替换被脱敏的属性值
+const RedactedValue = "[REDACTED]"
+
+// redactor 按键名模式隐藏敏感属性，模式不区分大小写，支持 path.Match 的通配符，
+// 如 "password"、"*token*"、"*_secret"
+type redactor struct {
+	patterns []string
+}
+
+func newRedactor(patterns []string) *redactor {
+	if len(patterns) == 0 {
+		return nil
+	}
+	r := &redactor{}
+	for _, p := range patterns {
+		r.patterns = append(r.patterns, strings.ToLower(p))
+	}
+	return r
+}
+
+func (rd *redactor) match(key string) bool {
+	key = strings.ToLower(key)
+	for _, p := range rd.patterns {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// attr 返回脱敏后的属性，递归处理分组和以字符串为键的 map；没有改动时 changed 为 false
+func (rd *redactor) attr(a slog.Attr) (slog.Attr, bool) {
+	if a.Key != "" && rd.match(a.Key) {
+		return slog.String(a.Key, RedactedValue), true
+	}
+	v := a.Value
+	if v.Kind() == slog.KindLogValuer && !isOwnValue(v) {
+		v = v.Resolve()
+	}
+	switch v.Kind() {
+	case slog.KindGroup:
+		if g, ok := mapGroup(v, rd.attr); ok {
+			return slog.Attr{Key: a.Key, Value: g}, true
+		}
+	case slog.KindAny:
+		if m, ok := rd.mapValue(v.Any()); ok {
+			return slog.Any(a.Key, m), true
+		}
+	}
+	return a, false
+}
+
+// mapGroup 用 fn 改写分组中的每个属性，没有改动时不分配新的分组
+func mapGroup(v slog.Value, fn func(slog.Attr) (slog.Attr, bool)) (slog.Value, bool) {
+	group := v.Group()
+	var attrs []slog.Attr
+	for i, ga := range group {
+		ga, changed := fn(ga)
+		if changed && attrs == nil {
+			attrs = append(make([]slog.Attr, 0, len(group)), group[:i]...)
+		}
+		// 去掉被删除的属性，slog 会为分组中的空属性多写一个分隔符
+		if attrs != nil && !ga.Equal(slog.Attr{}) {
+			attrs = append(attrs, ga)
+		}
+	}
+	if attrs == nil {
+		return v, false
+	}
+	return slog.GroupValue(attrs...), true
+}
+
+// mapValue 脱敏以字符串为键的 map（包括嵌套的 map），有改动时返回新的 map[string]any
+func (rd *redactor) mapValue(x any) (any, bool) {
+	rv := reflect.ValueOf(x)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	changed := false
+	out := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k := iter.Key().String()
+		v := iter.Value().Interface()
+		if rd.match(k) {
+			out[k] = RedactedValue
+			changed = true
+			continue
+		}
+		if m, ok := rd.mapValue(v); ok {
+			v = m
+			changed = true
+		}
+		out[k] = v
+	}
+	return out, changed
+}
+
+// isOwnValue 报告 v 是否是 xslog 自己的特殊值，这些值由输出按自己的格式处理，不能提前求值
+func isOwnValue(v slog.Value) bool {
+	switch v.Any().(type) {
+	case errorValue, stackValue, repeatCount, byteSize, humanDuration:
+		return true
+	}
+	return false
+}
+
+// attrTransform 在属性交给输出之前改写属性，没有改动时 changed 为 false
+type attrTransform interface {
+	attr(a slog.Attr) (_ slog.Attr, changed bool)
+}
+
+// messageTransform 由同时需要改写消息的 attrTransform 实现
+type messageTransform interface {
+	message(msg string) (_ string, changed bool)
+}
+
+// newTransforms 按配置创建属性改写链，顺序即执行顺序
+func newTransforms(config LogConfig) []attrTransform {
+	var ts []attrTransform
+	// 先展开错误链，之后的脱敏规则也作用于各个原因
+	if config.ErrorCauses {
+		ts = append(ts, causeExpander{})
+	}
+	if rd := newRedactor(config.Redact); rd != nil {
+		ts = append(ts, rd)
+	}
+	if sc := newScrubber(config.Scrub); sc != nil {
+		ts = append(ts, sc)
+	}
+	return ts
+}
+
+func (ml *Logger) transformAttr(a slog.Attr) (slog.Attr, bool) {
+	changed := false
+	for _, t := range ml.transforms {
+		var c bool
+		a, c = t.attr(a)
+		changed = changed || c
+	}
+	return a, changed
+}
+
+// transformAttrs 在属性交给输出之前统一改写（脱敏等），用于 With 附加的属性
+func (ml *Logger) transformAttrs(attrs []slog.Attr) []slog.Attr {
+	if len(ml.transforms) == 0 {
+		return attrs
+	}
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i], _ = ml.transformAttr(a)
+	}
+	return out
+}
+
+// transformRecord 与 transformAttrs 相同，用于记录的消息和属性；没有改动时原样返回
+func (ml *Logger) transformRecord(r slog.Record) slog.Record {
+	if len(ml.transforms) == 0 {
+		return r
+	}
+	msg, changed := r.Message, false
+	for _, t := range ml.transforms {
+		if mt, ok := t.(messageTransform); ok {
+			var c bool
+			msg, c = mt.message(msg)
+			changed = changed || c
+		}
+	}
+	if !changed {
+		r.Attrs(func(a slog.Attr) bool {
+			_, changed = ml.transformAttr(a)
+			return !changed
+		})
+	}
+	if !changed {
+		return r
+	}
+	r2 := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		a, _ = ml.transformAttr(a)
+		r2.AddAttrs(a)
+		return true
+	})
+	return r2
+}
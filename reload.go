@@ -0,0 +1,172 @@
+package xslog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce 合并编辑器保存时产生的多次文件事件
+const reloadDebounce = 100 * time.Millisecond
+
+// ErrRestartRequired 表示配置文件中有 Reload 不能在运行时应用的改动，需要重新创建 logger 才会生效
+var ErrRestartRequired = errors.New("log config changes require a restart")
+
+// reloadableKeys 是 Reload 会应用的配置项，其余配置项只在创建 logger 时生效
+var reloadableKeys = map[string]bool{
+	"console.enabled": true,
+	"console.level":   true,
+	"file.enabled":    true,
+	"file.path":       true,
+	"file.level":      true,
+	"levels":          true,
+	"ignore_env":      true,
+}
+
+// Reload 重新读取 NewLoggerFromFile 使用的配置文件，并在运行时应用级别（console.level、file.level）、
+// 输出开关（console.enabled、file.enabled）、文件路径（file.path）和 levels 规则。
+// 切换文件时先打开新文件再替换，不会丢失记录。配置文件有误时返回错误，logger 保持原配置。
+//
+// 其他配置项（如 redact、sampling、limits、attrs、audit、outputs、file.rotation 和控制台样式）
+// 只在创建 logger 时生效：它们与创建时不同时，Reload 仍会应用上面的配置项，
+// 然后返回包装了 ErrRestartRequired 的错误，错误信息中列出这些键
+func (ml *Logger) Reload() error {
+	if ml.configPath == "" {
+		return errors.New("logger was not created from a config file")
+	}
+	fc, err := loadConfigFile(ml.configPath)
+	if err != nil {
+		return err
+	}
+	if err := applyEnvLevels(&fc.config); err != nil {
+		return err
+	}
+
+	ml.SetConsoleLevel(fc.config.LevelForConsole)
+	ml.SetFileLevel(fc.config.LevelForFile)
+	ml.EnableConsole(fc.config.LogToConsole)
+	if fc.config.LogFilePath != "" {
+		if err := ml.ChangeFilePath(fc.config.LogFilePath); err != nil {
+			return err
+		}
+	}
+	if err := ml.EnableFile(fc.config.LogToFile); err != nil {
+		return err
+	}
+	ml.levelRules.replace(fc.levels)
+	if keys := changedKeys(ml.configRaw, fc.raw); len(keys) > 0 {
+		return fmt.Errorf("%s: %w", strings.Join(keys, ", "), ErrRestartRequired)
+	}
+	return nil
+}
+
+// changedKeys 返回 prev 和 next 之间不同且不能重载的配置项，console 和 file 按子键比较，按键名排序
+func changedKeys(prev, next map[string]any) []string {
+	var keys []string
+	diff := func(key string, a, b any) {
+		if !reloadableKeys[key] && !reflect.DeepEqual(a, b) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range unionKeys(prev, next) {
+		if key == "console" || key == "file" {
+			a, _ := prev[key].(map[string]any)
+			b, _ := next[key].(map[string]any)
+			for _, sub := range unionKeys(a, b) {
+				diff(key+"."+sub, a[sub], b[sub])
+			}
+			continue
+		}
+		diff(key, prev[key], next[key])
+	}
+	return keys
+}
+
+// unionKeys 返回 a 和 b 中所有的键，按键名排序
+func unionKeys(a, b map[string]any) []string {
+	keys := sortedKeys(a)
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WatchConfig 监听配置文件，文件变化时自动 Reload。
+// 重载失败会以 Error 级别记录，logger 保持原配置；有不能重载的改动（ErrRestartRequired）时以 Warn 级别记录。
+// 返回的 stop 用于停止监听，还没有执行的重载也随之取消
+func (ml *Logger) WatchConfig() (stop func() error, err error) {
+	if ml.configPath == "" {
+		return nil, errors.New("logger was not created from a config file")
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// 监听所在目录而不是文件本身，编辑器用重命名方式保存时也能收到事件
+	target := filepath.Clean(ml.configPath)
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	var (
+		mu      sync.Mutex // 保护 timer 和 stopped
+		timer   *time.Timer
+		stopped bool
+	)
+	reload := func() {
+		err := ml.Reload()
+		switch {
+		case errors.Is(err, ErrRestartRequired):
+			ml.Warn("log config reloaded partially", Err(err))
+		case err != nil:
+			ml.Error("failed to reload log config", Err(err))
+		}
+	}
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != target || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				mu.Lock()
+				if timer != nil {
+					timer.Stop()
+				}
+				if !stopped {
+					timer = time.AfterFunc(reloadDebounce, reload)
+				}
+				mu.Unlock()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				ml.Error("log config watcher error", Err(err))
+			}
+		}
+	}()
+	return func() error {
+		err := watcher.Close()
+		mu.Lock()
+		stopped = true
+		if timer != nil {
+			timer.Stop()
+		}
+		mu.Unlock()
+		return err
+	}, nil
+}
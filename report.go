@@ -0,0 +1,120 @@
+package xslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// 内置输出在 OnError 回调中的名称
+const (
+	SinkConsole = "console"
+	SinkFile    = "file"
+)
+
+// errorReporter 收集各个输出的写入错误，slog 会忽略 Handle 返回的错误，
+// 不报告的话磁盘写满、管道断开等问题完全不可见
+type errorReporter struct {
+	hook  atomic.Pointer[func(sink string, err error)]
+	count atomic.Uint64
+	stats *logStats // 为 nil 时不统计
+	self  *selfLog  // 没有 OnError 回调时输出错误的地方
+
+	onWrite atomic.Pointer[func(sink string, level slog.Level, d time.Duration, err error)]
+}
+
+// start 返回写入开始的时间，没有设置 OnWrite 时为零值，不调用 time.Now
+func (e *errorReporter) start() time.Time {
+	if e.onWrite.Load() == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// result 统计一次写入的结果，失败时报告错误；start 是 e.start 的返回值
+func (e *errorReporter) result(sink string, level slog.Level, start time.Time, err error) {
+	if fn := e.onWrite.Load(); fn != nil && !start.IsZero() {
+		(*fn)(sink, level, time.Since(start), err)
+	}
+	if err == nil {
+		if e.stats != nil {
+			e.stats.written.add(sinkLevel{sink, level})
+		}
+		return
+	}
+	e.report(sink, err)
+}
+
+func (e *errorReporter) report(sink string, err error) {
+	if err == nil {
+		return
+	}
+	e.count.Add(1)
+	if e.stats != nil {
+		e.stats.errors.add(sink)
+	}
+	if fn := e.hook.Load(); fn != nil {
+		(*fn)(sink, err)
+		return
+	}
+	// 熔断期间每条记录都会失败，默认只打印导致熔断的错误
+	if errors.Is(err, ErrCircuitOpen) {
+		return
+	}
+	e.self.printf("write "+sink, "write to %s failed: %v", sink, err)
+}
+
+// OnError 设置输出写入失败时的回调，sink 为 SinkConsole、SinkFile 或 Output.Name；
+// 默认把错误打印到 LogConfig.InternalLog（标准错误），同一输出的错误每 10 秒最多打印一次。回调在写日志的 goroutine 中同步调用，
+// 不要在其中通过同一个 logger 写日志
+func (ml *Logger) OnError(fn func(sink string, err error)) {
+	if fn == nil {
+		ml.sinkErrors.hook.Store(nil)
+		return
+	}
+	ml.sinkErrors.hook.Store(&fn)
+}
+
+// OnWrite 设置每次输出写入一条记录后的回调，d 为写入耗时（异步模式下不含排队时间），
+// 用于统计写入延迟，如 xslogprom 的直方图；fn 为 nil 时取消。
+// 回调在写日志的 goroutine 或异步队列中同步调用，应尽快返回
+func (ml *Logger) OnWrite(fn func(sink string, level slog.Level, d time.Duration, err error)) {
+	if fn == nil {
+		ml.sinkErrors.onWrite.Store(nil)
+		return
+	}
+	ml.sinkErrors.onWrite.Store(&fn)
+}
+
+// WriteErrors 返回各个输出写入失败的累计次数
+func (ml *Logger) WriteErrors() uint64 {
+	return ml.sinkErrors.count.Load()
+}
+
+// reportingHandler 把 inner 返回的错误报告给 errorReporter
+type reportingHandler struct {
+	sink   string
+	report *errorReporter
+	inner  slog.Handler
+}
+
+func (h *reportingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *reportingHandler) Handle(ctx context.Context, r slog.Record) error {
+	start := h.report.start()
+	err := h.inner.Handle(ctx, r)
+	h.report.result(h.sink, r.Level, start, err)
+	return err
+}
+
+func (h *reportingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &reportingHandler{sink: h.sink, report: h.report, inner: h.inner.WithAttrs(attrs)}
+}
+
+func (h *reportingHandler) WithGroup(name string) slog.Handler {
+	return &reportingHandler{sink: h.sink, report: h.report, inner: h.inner.WithGroup(name)}
+}
@@ -0,0 +1,99 @@
+package xslog
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy 的默认值
+const (
+	defaultRetryAttempts = 3
+	defaultRetryBackoff  = 100 * time.Millisecond
+	defaultRetryMax      = 5 * time.Second
+	defaultRetryJitter   = 0.2
+)
+
+// RetryPolicy 是远程输出共用的重试策略：失败后等待 InitialBackoff，之后每次加倍，
+// 最多等待 MaxBackoff；每次等待时间随机浮动 ±Jitter，避免大量实例同时重试
+type RetryPolicy struct {
+	MaxAttempts    int           // 包括第一次在内的最多尝试次数，默认 3，1 表示不重试
+	InitialBackoff time.Duration // 默认 100ms
+	MaxBackoff     time.Duration // 默认 5s
+	Jitter         float64       // 0~1，默认 0.2，设为负数关闭
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultRetryAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultRetryBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMax
+	}
+	if p.Jitter == 0 {
+		p.Jitter = defaultRetryJitter
+	}
+	return p
+}
+
+// Do 调用 fn 直到成功、返回 Permanent 错误、用完尝试次数或 ctx 结束，返回最后一次的错误
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	p = p.withDefaults()
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if attempt >= p.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(p.jitter(backoff))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		}
+		backoff *= 2
+		if backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+func (p RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return d
+	}
+	j := p.Jitter
+	if j > 1 {
+		j = 1
+	}
+	return time.Duration(float64(d) * (1 + j*(2*rand.Float64()-1)))
+}
+
+// Permanent 标记不应重试的错误（如远端返回 400），RetryPolicy.Do 遇到时立即返回原始错误
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
@@ -0,0 +1,136 @@
+package xslog
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// newRingSink 返回只保留最近 size 条记录、不按级别过滤的 ObserverSink
+func newRingSink(size int) *ObserverSink {
+	o := NewObserverSink(nil)
+	o.store.max = size
+	return o
+}
+
+// Recent 返回环形缓冲（LogConfig.RingBuffer）中最近 n 条 level 及以上级别的记录，按时间先后排列；
+// n 小于等于 0 时返回缓冲中所有满足级别的记录，没有配置环形缓冲时返回 nil。
+// 适合在崩溃报告或诊断包中附上出错前的上下文
+//
+//	report.Logs = logger.Recent(slog.LevelDebug, 200)
+func (ml *Logger) Recent(level slog.Level, n int) ObservedRecords {
+	if ml.ring == nil {
+		return nil
+	}
+	records := ml.ring.Records().FilterMinLevel(level)
+	if n > 0 && len(records) > n {
+		records = records[len(records)-n:]
+	}
+	return records
+}
+
+// record 把记下的记录还原为 slog.Record，用于重新编码
+func (r ObservedRecord) record() slog.Record {
+	rec := slog.NewRecord(r.Time, r.Level, r.Message, 0)
+	rec.AddAttrs(r.Attrs...)
+	return rec
+}
+
+// RecentHandler 返回以 HTTP 查看环形缓冲中最近记录的 http.Handler，只接受 GET，支持以下查询参数：
+//
+//	n       最多返回的条数，默认 100，0 表示全部
+//	level   最低级别，如 warn，默认返回所有级别
+//	since   只返回这之后的记录，可以是时长（如 15m，表示最近 15 分钟）或 RFC 3339 时间
+//	q       消息中包含的文本
+//	format  json（默认，与日志文件相同格式的 JSON 数组）或 text（不带颜色的控制台格式，每行前加时间）
+//
+// 记录中可能含有敏感信息（PII 标记的属性按 PIIRedact 输出），挂载时应自行做好访问控制
+//
+//	mux.Handle("/debug/logs", logger.RecentHandler())
+func (ml *Logger) RecentHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if ml.ring == nil {
+			http.Error(w, "ring buffer not configured", http.StatusNotFound)
+			return
+		}
+		q := r.URL.Query()
+		query, err := parseRecordQuery(q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		records := ml.ring.Records().Query(query)
+
+		var buf bytes.Buffer
+		switch format := q.Get("format"); format {
+		case "", "json":
+			w.Header().Set("Content-Type", "application/json")
+			buf.Write(appendRecordsJSON(nil, records))
+		case "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			h := NewTxtColoredHandler(&buf, nil)
+			h.noColor = true
+			// 控制台格式不带时间，每行前面加上记录的时间
+			for _, rec := range records {
+				buf.WriteString(rec.Time.Format(consoleTimeFormat))
+				buf.WriteByte(' ')
+				_ = h.Handle(r.Context(), rec.record())
+			}
+		default:
+			http.Error(w, "format: expected json or text, got "+strconv.Quote(format), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// parseRecordQuery 解析 RecentHandler 和 ViewerHandler 共用的查询参数 n、level、since 和 q
+func parseRecordQuery(v url.Values) (Query, error) {
+	q := Query{Limit: 100, Message: v.Get("q")}
+	if s := v.Get("n"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return Query{}, fmt.Errorf("n: invalid count %q", s)
+		}
+		q.Limit = n
+	}
+	if s := v.Get("level"); s != "" {
+		level, err := ParseLevel(s)
+		if err != nil {
+			return Query{}, fmt.Errorf("level: %w", err)
+		}
+		q.MinLevel = level
+	}
+	if s := v.Get("since"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			q.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+			q.Since = t
+		} else {
+			return Query{}, fmt.Errorf("since: expected a duration or RFC 3339 time, got %q", s)
+		}
+	}
+	return q, nil
+}
+
+// appendRecordsJSON 把记录按日志文件的 JSON 格式编码为一个数组，PII 标记的属性按 PIIRedact 输出
+func appendRecordsJSON(buf []byte, records ObservedRecords) []byte {
+	buf = append(buf, '[')
+	for i, rec := range records {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		b, _ := MarshalRecord(rec.record())
+		buf = append(buf, b...)
+	}
+	return append(buf, "]\n"...)
+}
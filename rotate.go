@@ -0,0 +1,120 @@
+package xslog
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RotationConfig 配置日志文件轮转：文件写到 MaxSize 字节时改名为带时间的旧文件
+// （如 app.log.20261014-162300.000），再在原路径打开新文件继续写；
+// OpenLogFiles 可以按时间顺序读出原文件和轮转出的所有旧文件。旧文件在每次轮转后按 MaxBackups 和 MaxAge 清理
+type RotationConfig struct {
+	MaxSize    int64         // 文件达到这么多字节时轮转，0 为不轮转
+	MaxBackups int           // 最多保留的旧文件数，0 为全部保留
+	MaxAge     time.Duration // 删除修改时间早于这么久的旧文件，0 为不按时间删除
+}
+
+// rotationTimeFormat 是轮转出的旧文件名中的时间格式，以数字开头，见 rotatedFiles
+const rotationTimeFormat = "20060102-150405.000"
+
+// shouldRotate 返回写入 n 字节前是否需要轮转，调用方持有 w.mu
+func (w *fileWriter) shouldRotate(n int) bool {
+	return w.rotation != nil && w.rotation.MaxSize > 0 && w.size > 0 && w.size+int64(n) > w.rotation.MaxSize
+}
+
+// rotate 把当前日志文件改名为旧文件，再在原路径打开新文件；任何一步失败时报告错误，
+// 继续写原来的文件，写满 MaxSize 后再重试。调用方持有 w.mu
+func (w *fileWriter) rotate() {
+	if err := w.syncLocked(); err != nil {
+		w.report(fmt.Errorf("log rotation failed: %w", err))
+		return
+	}
+	path := w.file.Name()
+	backup := rotatedName(path, time.Now())
+	if err := os.Rename(path, backup); err != nil {
+		w.size = 0
+		w.report(fmt.Errorf("log rotation failed: %w", err))
+		return
+	}
+	file, err := openLogFile(path)
+	if err != nil {
+		// 新文件打不开时接着写已经改名的文件，不丢记录
+		w.size = 0
+		w.report(fmt.Errorf("log rotation failed, still writing to %s: %w", backup, err))
+		return
+	}
+	if err := w.file.Close(); err != nil {
+		w.report(fmt.Errorf("failed to close rotated log file: %w", err))
+	}
+	w.file = file
+	if w.buf != nil {
+		w.buf.Reset(file)
+	}
+	w.size = 0
+	if w.chain != nil {
+		// 每个文件的哈希链都从头开始，可以单独用 VerifyChain 校验
+		w.chain.prev = nil
+	}
+	w.prune(path)
+}
+
+// rotatedName 返回 path 在 t 时刻轮转出的旧文件名，同一毫秒内多次轮转时加上序号
+func rotatedName(path string, t time.Time) string {
+	name := path + "." + t.Format(rotationTimeFormat)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(name); errors.Is(err, fs.ErrNotExist) {
+			return name
+		}
+		name = path + "." + t.Format(rotationTimeFormat) + "-" + strconv.Itoa(i)
+	}
+}
+
+// prune 按 MaxBackups 和 MaxAge 删除 path 轮转出的旧文件，调用方持有 w.mu
+func (w *fileWriter) prune(path string) {
+	if w.rotation.MaxBackups <= 0 && w.rotation.MaxAge <= 0 {
+		return
+	}
+	backups, err := rotatedFiles(path)
+	if err != nil {
+		w.report(fmt.Errorf("failed to list rotated log files: %w", err))
+		return
+	}
+	cutoff := time.Now().Add(-w.rotation.MaxAge)
+	for i, b := range backups {
+		if (w.rotation.MaxBackups > 0 && i >= w.rotation.MaxBackups) ||
+			(w.rotation.MaxAge > 0 && b.mod.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				w.report(fmt.Errorf("failed to remove rotated log file: %w", err))
+			}
+		}
+	}
+}
+
+type rotatedFile struct {
+	path string
+	mod  time.Time
+}
+
+// rotatedFiles 返回 path 轮转出的旧文件，最新的在前
+func rotatedFiles(path string) ([]rotatedFile, error) {
+	matches, err := filepath.Glob(globEscape(path) + ".[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	files := make([]rotatedFile, 0, len(matches))
+	for _, p := range matches {
+		fi, err := os.Stat(p)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, rotatedFile{p, fi.ModTime()})
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].mod.After(files[j].mod) })
+	return files, nil
+}
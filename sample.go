@@ -0,0 +1,112 @@
+package xslog
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingConfig 的默认值
+const (
+	defaultSamplingTick = time.Second
+	samplerCounters     = 4096
+)
+
+// SampleRate 是一种采样规则：每个周期内同一级别、同一消息的前 First 条全部记录，
+// 之后每 Thereafter 条记录一条（Thereafter 为 0 时之后全部丢弃）。零值表示不采样
+type SampleRate struct {
+	First      int
+	Thereafter int
+}
+
+// SamplingConfig 配置采样，与 zap 的 sampler 相同，用于限制高频代码路径产生的日志量，
+// 同时保留有统计意义的样本
+//
+//	Sampling: &xslog.SamplingConfig{
+//		Default: xslog.SampleRate{First: 100, Thereafter: 100},
+//		Levels:  map[slog.Level]xslog.SampleRate{slog.LevelError: {}}, // 错误不采样
+//	}
+type SamplingConfig struct {
+	Tick    time.Duration             // 计数周期，默认 1s
+	Default SampleRate                // Levels 中没有列出的级别使用的规则
+	Levels  map[slog.Level]SampleRate // 按级别单独设置的规则
+}
+
+// sampler 按级别和消息计数，计数器数量固定，不同消息可能共用一个计数器
+type sampler struct {
+	tick     int64
+	def      SampleRate
+	levels   map[slog.Level]SampleRate
+	counters [samplerCounters]sampleCounter
+	dropped  atomic.Uint64
+}
+
+type sampleCounter struct {
+	resetAt atomic.Int64
+	n       atomic.Uint64
+}
+
+func newSampler(cfg *SamplingConfig) *sampler {
+	if cfg == nil {
+		return nil
+	}
+	tick := cfg.Tick
+	if tick <= 0 {
+		tick = defaultSamplingTick
+	}
+	s := &sampler{tick: int64(tick), def: cfg.Default, levels: make(map[slog.Level]SampleRate, len(cfg.Levels))}
+	for level, rate := range cfg.Levels {
+		s.levels[level] = rate
+	}
+	return s
+}
+
+// allow 报告记录是否通过采样
+func (s *sampler) allow(r slog.Record) bool {
+	if s == nil {
+		return true
+	}
+	rate, ok := s.levels[r.Level]
+	if !ok {
+		rate = s.def
+	}
+	if rate.First <= 0 && rate.Thereafter <= 0 {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(r.Message))
+	idx := (h.Sum32() ^ uint32(r.Level)*16777619) % samplerCounters
+	n := s.counters[idx].inc(r.Time.UnixNano(), s.tick)
+	if n <= uint64(rate.First) {
+		return true
+	}
+	if rate.Thereafter > 0 && (n-uint64(rate.First))%uint64(rate.Thereafter) == 0 {
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+// inc 返回本周期内的计数，周期结束后从 1 重新计数
+func (c *sampleCounter) inc(now, tick int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.n.Add(1)
+	}
+	c.n.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+tick) {
+		// 其他 goroutine 已经开始了新周期
+		return c.n.Add(1)
+	}
+	return 1
+}
+
+// Sampled 返回因采样被丢弃的记录数
+func (ml *Logger) Sampled() uint64 {
+	if ml.sampler == nil {
+		return 0
+	}
+	return ml.sampler.dropped.Load()
+}
@@ -0,0 +1,89 @@
+package xslog
+
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// appendEscaped 把 s 追加到 buf，并转义换行、回车、其他控制字符（包括 ANSI 转义序列的 ESC）和无效的 UTF-8 字节，
+// 防止不可信的输入在控制台中伪造日志行或注入终端控制序列；制表符原样保留。
+// JSON 输出不需要它，控制字符已经由 JSON 编码转义
+func appendEscaped(buf []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c < 0x7f || c == '\t' {
+			i++
+			continue
+		}
+		if c < utf8.RuneSelf {
+			buf = append(buf, s[start:i]...)
+			buf = appendEscapedByte(buf, c)
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			// 无效的 UTF-8 字节（如单独的 0x9B）在 8 位终端中同样是 C1 控制字符
+			buf = append(buf, s[start:i]...)
+			buf = appendEscapedByte(buf, c)
+			i++
+			start = i
+			continue
+		}
+		// C1 控制字符（如编码为 UTF-8 的 CSI U+009B）同样可以被终端解释
+		if r >= 0x80 && r <= 0x9f {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\u00`...)
+			buf = strconv.AppendUint(buf, uint64(r), 16)
+			start = i + size
+		}
+		i += size
+	}
+	return append(buf, s[start:]...)
+}
+
+func appendEscapedByte(buf []byte, c byte) []byte {
+	switch c {
+	case '\n':
+		return append(buf, `\n`...)
+	case '\r':
+		return append(buf, `\r`...)
+	}
+	const hex = "0123456789abcdef"
+	return append(buf, '\\', 'x', hex[c>>4], hex[c&0xf])
+}
+
+// appendQuoted 与 appendEscaped 相同，但 s 为空或含有空白、'='、'"' 和控制字符时
+// 按 logfmt 的习惯加上双引号，引号内的 '"' 和 '\' 前加反斜杠，
+// 这样控制台中 key="hello world" 这样的值没有歧义，捕获的输出也可以按 logfmt 解析
+func appendQuoted(buf []byte, s string) []byte {
+	if !needsQuote(s) {
+		return appendEscaped(buf, s)
+	}
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' || c == '\\' {
+			buf = appendEscaped(buf, s[start:i])
+			buf = append(buf, '\\', c)
+			start = i + 1
+		}
+	}
+	buf = appendEscaped(buf, s[start:])
+	return append(buf, '"')
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r >= 0x80 && (r <= 0x9f || unicode.IsSpace(r)) {
+			return true
+		}
+	}
+	return false
+}
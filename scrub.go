@@ -0,0 +1,107 @@
+package xslog
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+)
+
+// ScrubRule 把消息和字符串属性值中匹配 Pattern 的部分替换为 Replacement，
+// Replacement 支持 regexp.ReplaceAllString 的 $1 等引用
+type ScrubRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// 常用的改写规则
+var (
+	// ScrubCreditCard 隐藏 13~19 位、可以用空格或短横线分隔的卡号
+	ScrubCreditCard = ScrubRule{
+		Pattern:     regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Replacement: "[CARD]",
+	}
+	// ScrubBearerToken 隐藏 Authorization 头中的 Bearer 令牌
+	ScrubBearerToken = ScrubRule{
+		Pattern:     regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+		Replacement: "${1}[REDACTED]",
+	}
+	// ScrubEmail 隐藏邮箱地址
+	ScrubEmail = ScrubRule{
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Replacement: "[EMAIL]",
+	}
+)
+
+type scrubber struct {
+	rules []ScrubRule
+}
+
+func newScrubber(rules []ScrubRule) *scrubber {
+	if len(rules) == 0 {
+		return nil
+	}
+	return &scrubber{rules: rules}
+}
+
+func (sc *scrubber) message(msg string) (string, bool) {
+	return sc.string(msg)
+}
+
+func (sc *scrubber) string(s string) (string, bool) {
+	changed := false
+	for _, rule := range sc.rules {
+		if rule.Pattern.MatchString(s) {
+			s = rule.Pattern.ReplaceAllString(s, rule.Replacement)
+			changed = true
+		}
+	}
+	return s, changed
+}
+
+// attr 改写字符串值和错误信息，递归处理分组
+func (sc *scrubber) attr(a slog.Attr) (slog.Attr, bool) {
+	v := a.Value
+	if ev, ok := v.Any().(errorValue); ok {
+		changed := false
+		if msg, c := sc.string(ev.err.Error()); c {
+			ev.typ, ev.err = ev.errType(), errors.New(msg)
+			changed = true
+		}
+		copied := false
+		for i, cause := range ev.causes {
+			if msg, c := sc.string(cause.Message); c {
+				// causes 与原属性共用，改写前先复制
+				if !copied {
+					ev.causes = append([]errorCause(nil), ev.causes...)
+					copied = true
+				}
+				ev.causes[i].Message = msg
+				changed = true
+			}
+		}
+		if changed {
+			return slog.Any(a.Key, ev), true
+		}
+		return a, false
+	}
+	if v.Kind() == slog.KindLogValuer && !isOwnValue(v) {
+		v = v.Resolve()
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		if s, changed := sc.string(v.String()); changed {
+			return slog.String(a.Key, s), true
+		}
+	case slog.KindGroup:
+		if g, changed := mapGroup(v, sc.attr); changed {
+			return slog.Attr{Key: a.Key, Value: g}, true
+		}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			if s, changed := sc.string(err.Error()); changed {
+				return slog.String(a.Key, s), true
+			}
+		}
+	}
+	return a, false
+}
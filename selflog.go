@@ -0,0 +1,63 @@
+package xslog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 同一类内部问题在这个间隔内最多输出一次
+const selfLogInterval = 10 * time.Second
+
+// selfLog 输出 xslog 自身的问题（写入失败、磁盘写满、异步队列丢弃记录等），
+// 直接写到 LogConfig.InternalLog，不经过 Logger，不会递归到出问题的输出中；
+// 同一类消息按 selfLogInterval 限流，被省略的条数附在下一次输出的末尾
+type selfLog struct {
+	w     io.Writer
+	mu    sync.Mutex
+	kinds map[string]*selfLogKind
+	now   func() time.Time
+}
+
+type selfLogKind struct {
+	last       time.Time
+	suppressed int
+}
+
+func newSelfLog(w io.Writer) *selfLog {
+	if w == nil {
+		w = os.Stderr
+	}
+	return &selfLog{w: w, kinds: map[string]*selfLogKind{}, now: time.Now}
+}
+
+// printf 输出一条 kind 类的消息，kind 相同的消息共用限流
+func (l *selfLog) printf(kind, format string, args ...any) {
+	if l == nil {
+		fmt.Fprintf(os.Stderr, "xslog: "+format+"\n", args...)
+		return
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	k := l.kinds[kind]
+	if k == nil {
+		k = &selfLogKind{}
+		l.kinds[kind] = k
+	} else if now.Sub(k.last) < selfLogInterval {
+		k.suppressed++
+		return
+	}
+	buf := append([]byte("xslog: "), fmt.Sprintf(format, args...)...)
+	if k.suppressed > 0 {
+		buf = append(buf, " ("...)
+		buf = strconv.AppendInt(buf, int64(k.suppressed), 10)
+		buf = append(buf, " similar messages suppressed)"...)
+	}
+	buf = append(buf, '\n')
+	_, _ = l.w.Write(buf)
+	k.last, k.suppressed = now, 0
+}
@@ -0,0 +1,247 @@
+package xslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CEFFormat 输出 ArcSight CEF（Common Event Format）：
+//
+//	CEF:0|Vendor|Product|Version|消息|消息|严重程度|rt=毫秒时间戳 key=value ...
+//
+// 级别映射为 0-10 的严重程度，属性（分组以 "." 连接）作为扩展字段
+type CEFFormat struct {
+	Vendor  string // 默认为 "xslog"
+	Product string // 默认为 "xslog"
+	Version string // 默认为 "1.0"
+}
+
+// LEEFFormat 输出 QRadar LEEF 1.0，以制表符分隔属性：
+//
+//	LEEF:1.0|Vendor|Product|Version|消息|devTime=毫秒时间戳	sev=严重程度	key=value ...
+type LEEFFormat struct {
+	Vendor  string // 默认为 "xslog"
+	Product string // 默认为 "xslog"
+	Version string // 默认为 "1.0"
+}
+
+// siemSeverity 把级别映射为 CEF/LEEF 使用的 0-10 严重程度
+func siemSeverity(level slog.Level) int {
+	switch {
+	case level < slog.LevelDebug:
+		return 0
+	case level < slog.LevelInfo:
+		return 1
+	case level < LevelNotice:
+		return 3
+	case level < slog.LevelWarn:
+		return 4
+	case level < slog.LevelError:
+		return 6
+	case level < LevelFatal:
+		return 8
+	}
+	return 10
+}
+
+func siemHeader(buf []byte, prefix, vendor, product, version string) []byte {
+	buf = append(buf, prefix...)
+	for _, s := range []string{vendor, product, version} {
+		buf = appendHeaderField(buf, s)
+		buf = append(buf, '|')
+	}
+	return buf
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func (f CEFFormat) encoder(w io.Writer) slog.Handler {
+	vendor, product, version := defaultString(f.Vendor, "xslog"), defaultString(f.Product, "xslog"), defaultString(f.Version, "1.0")
+	return &flatHandler{w: w, format: func(buf []byte, r slog.Record, attrs []flatAttr) []byte {
+		buf = siemHeader(buf, "CEF:0|", vendor, product, version)
+		buf = appendHeaderField(buf, r.Message)
+		buf = append(buf, '|')
+		buf = appendHeaderField(buf, r.Message)
+		buf = append(buf, '|')
+		buf = strconv.AppendInt(buf, int64(siemSeverity(r.Level)), 10)
+		buf = append(buf, "|rt="...)
+		buf = strconv.AppendInt(buf, r.Time.UnixMilli(), 10)
+		for _, a := range attrs {
+			buf = append(buf, ' ')
+			buf = appendExtensionKey(buf, a.key)
+			buf = append(buf, '=')
+			buf = appendCEFValue(buf, flatValue(a.value))
+		}
+		return append(buf, '\n')
+	}}
+}
+
+func (f LEEFFormat) encoder(w io.Writer) slog.Handler {
+	vendor, product, version := defaultString(f.Vendor, "xslog"), defaultString(f.Product, "xslog"), defaultString(f.Version, "1.0")
+	return &flatHandler{w: w, format: func(buf []byte, r slog.Record, attrs []flatAttr) []byte {
+		buf = siemHeader(buf, "LEEF:1.0|", vendor, product, version)
+		buf = appendHeaderField(buf, r.Message)
+		buf = append(buf, "|devTime="...)
+		buf = strconv.AppendInt(buf, r.Time.UnixMilli(), 10)
+		buf = append(buf, "\tsev="...)
+		buf = strconv.AppendInt(buf, int64(siemSeverity(r.Level)), 10)
+		for _, a := range attrs {
+			buf = append(buf, '\t')
+			buf = appendExtensionKey(buf, a.key)
+			buf = append(buf, '=')
+			buf = appendLEEFValue(buf, flatValue(a.value))
+		}
+		return append(buf, '\n')
+	}}
+}
+
+// appendHeaderField 转义 CEF/LEEF 头部字段中的 "|" 和 "\"，换行替换为空格
+func appendHeaderField(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '|', '\\':
+			buf = append(buf, '\\', c)
+		case '\n', '\r':
+			buf = append(buf, ' ')
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// appendExtensionKey 只保留键名中的字母、数字、"_" 和 "."，其他字符替换为 "_"
+func appendExtensionKey(buf []byte, key string) []byte {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' {
+			buf = append(buf, c)
+		} else {
+			buf = append(buf, '_')
+		}
+	}
+	return buf
+}
+
+// appendCEFValue 按 CEF 扩展字段的规则转义 "\"、"=" 和换行
+func appendCEFValue(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '=':
+			buf = append(buf, '\\', c)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// appendLEEFValue 转义 LEEF 属性值中会破坏分隔的制表符和换行
+func appendLEEFValue(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\t':
+			buf = append(buf, `\t`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// flatAttr 是展开分组后的一个属性，key 以 "." 连接分组名
+type flatAttr struct {
+	key   string
+	value slog.Value // 已经求值
+}
+
+// flatHandler 把属性展开为 key=value 列表，再由 format 编码为一行文本，用于 CEF 等非 JSON 格式；
+// format 什么都不写时跳过这条记录
+type flatHandler struct {
+	w      io.Writer
+	format func(buf []byte, r slog.Record, attrs []flatAttr) []byte
+	prefix string     // WithGroup 打开的分组，如 "req."
+	attrs  []flatAttr // WithAttrs 附加的属性
+}
+
+func (h *flatHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *flatHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]flatAttr, len(h.attrs), len(h.attrs)+r.NumAttrs())
+	copy(attrs, h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendFlat(attrs, h.prefix, a)
+		return true
+	})
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if *buf = h.format(*buf, r, attrs); len(*buf) == 0 {
+		return nil
+	}
+	_, err := h.w.Write(*buf)
+	return err
+}
+
+func (h *flatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = h.attrs[:len(h.attrs):len(h.attrs)]
+	for _, a := range attrs {
+		h2.attrs = appendFlat(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *flatHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+func appendFlat(dst []flatAttr, prefix string, a slog.Attr) []flatAttr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return dst
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			dst = appendFlat(dst, prefix, ga)
+		}
+		return dst
+	}
+	return append(dst, flatAttr{key: prefix + a.Key, value: a.Value})
+}
+
+func flatValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if frames, ok := v.Any().([]string); ok {
+			return strings.Join(frames, "\n")
+		}
+	}
+	return v.String()
+}
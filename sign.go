@@ -0,0 +1,154 @@
+package xslog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SignatureKey 是签名模式下每条记录末尾保存 Ed25519 签名（base64）的字段名
+const SignatureKey = "sig"
+
+// ErrBadSignature 表示记录没有签名或签名与内容不符
+var ErrBadSignature = errors.New("bad record signature")
+
+// 签名字段在记录中的前缀，签名的内容是去掉这部分之后的整行
+var sigPrefix = []byte(`,"` + SignatureKey + `":"`)
+
+// recordSigner 在每条记录的末尾加上对其余内容的 Ed25519 签名
+type recordSigner struct {
+	key  ed25519.PrivateKey
+	line []byte
+}
+
+// sign 返回在 p 末尾加上签名后的记录；p 必须是以换行结尾的一条 JSON 记录，
+// 返回的切片在下一次调用前有效
+func (s *recordSigner) sign(p []byte) []byte {
+	body := bytes.TrimSuffix(p, []byte("\n"))
+	sig := ed25519.Sign(s.key, body)
+	s.line = append(s.line[:0], body[:len(body)-1]...)
+	if len(body) > 2 {
+		s.line = append(s.line, sigPrefix...)
+	} else {
+		s.line = append(s.line, sigPrefix[1:]...)
+	}
+	s.line = append(s.line, base64.StdEncoding.EncodeToString(sig)...)
+	return append(s.line, "\"}\n"...)
+}
+
+// VerifyRecord 校验用 LogConfig.FileSigningKey 签名的一条记录，失败时返回的错误包装 ErrBadSignature
+func VerifyRecord(line []byte, pub ed25519.PublicKey) error {
+	line = bytes.TrimRight(line, "\r\n")
+	i := bytes.LastIndex(line, sigPrefix[1:])
+	if i < 1 || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return fmt.Errorf("missing %s: %w", SignatureKey, ErrBadSignature)
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(line[i+len(sigPrefix)-1 : len(line)-2]))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", SignatureKey, ErrBadSignature)
+	}
+	// 还原签名前的内容：去掉签名字段和它前面的逗号
+	body := append([]byte(nil), line[:i]...)
+	if body[len(body)-1] == ',' {
+		body = body[:len(body)-1]
+	}
+	body = append(body, '}')
+	if !ed25519.Verify(pub, body, sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// VerifySignatures 校验日志文件中每条记录的签名，失败时指出第一条出问题的行号
+func VerifySignatures(path string, pub ed25519.PublicKey) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if verr := VerifyRecord(line, pub); verr != nil {
+				return fmt.Errorf("%s:%d: %w", path, n, verr)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// LoadSigningKey 从文件读取 Ed25519 私钥，支持 PEM（PKCS #8，openssl genpkey -algorithm ed25519 生成）
+// 和 base64 编码的 32 字节种子或 64 字节私钥
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSigningKey(data)
+}
+
+// ParseSigningKey 解析 LoadSigningKey 支持的私钥格式，可以用于从环境变量读取的密钥
+func ParseSigningKey(data []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signing key: %w", err)
+		}
+		if k, ok := key.(ed25519.PrivateKey); ok {
+			return k, nil
+		}
+		return nil, fmt.Errorf("invalid signing key: expected ed25519, got %T", key)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	}
+	return nil, fmt.Errorf("invalid signing key: unexpected length %d", len(raw))
+}
+
+// LoadVerifyKey 从文件读取 Ed25519 公钥，支持 PEM（PKIX）和 base64 编码的 32 字节公钥
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verify key: %w", err)
+		}
+		if k, ok := key.(ed25519.PublicKey); ok {
+			return k, nil
+		}
+		return nil, fmt.Errorf("invalid verify key: expected ed25519, got %T", key)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid verify key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid verify key: unexpected length %d", len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
@@ -0,0 +1,38 @@
+package xslog
+
+import (
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignaturesBatchedAsync(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "a.log")
+	ml, err := NewLogger(LogConfig{
+		LogToFile:      true,
+		LogFilePath:    path,
+		IgnoreEnv:      true,
+		FileHashChain:  true,
+		FileSigningKey: key,
+		Async:          AsyncConfig{Enabled: true, BatchSize: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		ml.Info("record", "i", i)
+	}
+	if err := ml.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignatures(path, pub); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyChain(path); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,30 @@
+package xslog
+
+import "log/slog"
+
+// signalLevels 是信号切换级别时循环的级别
+var signalLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// stepLevel 把 level 调低（more 为 true）或调高一档，在 Debug 和 Error 之间循环
+func stepLevel(level slog.Level, more bool) slog.Level {
+	if more {
+		for i := len(signalLevels) - 1; i >= 0; i-- {
+			if signalLevels[i] < level {
+				return signalLevels[i]
+			}
+		}
+		return signalLevels[len(signalLevels)-1]
+	}
+	for _, l := range signalLevels {
+		if l > level {
+			return l
+		}
+	}
+	return signalLevels[0]
+}
+
+// cycleLevels 同时调整控制台和文件的级别
+func (ml *Logger) cycleLevels(more bool) {
+	ml.SetConsoleLevel(stepLevel(ml.GetConsoleLevel(), more))
+	ml.SetFileLevel(stepLevel(ml.GetFileLevel(), more))
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package xslog
+
+// HandleLevelSignals 在不支持 SIGUSR1/SIGUSR2 的平台上不做任何事
+func (ml *Logger) HandleLevelSignals() (stop func()) {
+	return func() {}
+}
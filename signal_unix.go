@@ -0,0 +1,31 @@
+//go:build unix
+
+package xslog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleLevelSignals 开始监听信号：SIGUSR1 把控制台和文件级别调低一档（输出更多），
+// SIGUSR2 调高一档，在 Debug 和 Error 之间循环。返回的 stop 用于停止监听
+func (ml *Logger) HandleLevelSignals() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				ml.cycleLevels(sig == syscall.SIGUSR1)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
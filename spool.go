@@ -0,0 +1,265 @@
+package xslog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpoolConfig 的默认值
+const (
+	defaultSpoolSegmentSize = 4 << 20
+	defaultSpoolInterval    = time.Second
+	defaultSpoolRetry       = 5 * time.Second
+)
+
+const spoolSuffix = ".seg"
+
+// SpoolConfig 配置 Spool
+type SpoolConfig struct {
+	SegmentSize   int64           // 单个段文件的大小上限，默认 4MB
+	MaxBytes      int64           // 磁盘上保留的总字节数上限，超过时删除最旧的段；0 表示不限制
+	FlushInterval time.Duration   // 把新写入的记录交给发送 goroutine 的间隔，默认 1s
+	RetryInterval time.Duration   // 发送失败后的重试间隔，默认 5s
+	OnError       func(err error) // 发送失败时调用，默认忽略（离线时失败是预期的）
+}
+
+// Spool 是位于 logger 和远程输出之间的磁盘队列：Write 只追加到 dir 下的段文件，
+// 后台 goroutine 按顺序把段文件发送给 w，成功后删除。网络断开期间记录保存在磁盘上，
+// 恢复后继续发送；进程重启后也会发送上次遗留的段文件。
+//
+//	spool, err := xslog.NewSpool("/var/spool/app-logs", httpWriter, xslog.SpoolConfig{})
+//	logger, err := xslog.NewLoggerWithOptions(xslog.WithOutput(xslog.Output{Name: "remote", Writer: spool}))
+//	defer spool.Close() // 在 logger.Close 之后
+type Spool struct {
+	dir string
+	w   io.Writer
+	cfg SpoolConfig
+
+	mu      sync.Mutex
+	active  *os.File // 正在追加的段
+	size    int64    // active 的大小
+	nextSeq uint64
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewSpool(dir string, w io.Writer, cfg SpoolConfig) (*Spool, error) {
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = defaultSpoolSegmentSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultSpoolInterval
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultSpoolRetry
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &Spool{
+		dir:  dir,
+		w:    w,
+		cfg:  cfg,
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	segs, err := s.segments()
+	if err != nil {
+		return nil, err
+	}
+	if len(segs) > 0 {
+		s.nextSeq = segs[len(segs)-1] + 1
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *Spool) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		f, err := os.OpenFile(s.segmentPath(s.nextSeq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			return 0, err
+		}
+		s.active, s.size = f, 0
+		s.nextSeq++
+	}
+	n, err := s.active.Write(p)
+	s.size += int64(n)
+	if err == nil && s.size >= s.cfg.SegmentSize {
+		err = s.sealLocked()
+		s.notify()
+	}
+	return n, err
+}
+
+// Pending 返回磁盘上尚未发送的字节数
+func (s *Spool) Pending() int64 {
+	segs, _ := s.segments()
+	var total int64
+	for _, seq := range segs {
+		if fi, err := os.Stat(s.segmentPath(seq)); err == nil {
+			total += fi.Size()
+		}
+	}
+	return total
+}
+
+// Close 停止后台发送；尚未发送的记录留在磁盘上，下次 NewSpool 时继续发送
+func (s *Spool) Close() error {
+	close(s.stop)
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sealLocked()
+}
+
+// sealLocked 关闭正在追加的段，之后写入新的段，调用方持有 s.mu
+func (s *Spool) sealLocked() error {
+	if s.active == nil {
+		return nil
+	}
+	err := s.active.Close()
+	s.active = nil
+	return err
+}
+
+func (s *Spool) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Spool) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			_ = s.sealLocked()
+			s.mu.Unlock()
+		case <-s.wake:
+		case <-s.stop:
+			return
+		}
+		if err := s.send(); err != nil {
+			if s.cfg.OnError != nil {
+				s.cfg.OnError(err)
+			}
+			select {
+			case <-time.After(s.cfg.RetryInterval):
+			case <-s.stop:
+				return
+			}
+		}
+	}
+}
+
+// send 按顺序发送所有已封存的段
+func (s *Spool) send() error {
+	s.enforceLimit()
+	segs, err := s.segments()
+	if err != nil {
+		return err
+	}
+	for _, seq := range segs {
+		if s.isActive(seq) {
+			break
+		}
+		select {
+		case <-s.stop:
+			return nil
+		default:
+		}
+		path := s.segmentPath(seq)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sent, err := writeChunks(s.w, data, s.stop)
+		if sent < len(data) {
+			// 只保留未发送的部分，避免恢复后重复发送
+			if sent > 0 {
+				_ = os.WriteFile(path, data[sent:], 0666)
+			}
+			if err != nil {
+				return fmt.Errorf("spool: send %s: %w", filepath.Base(path), err)
+			}
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enforceLimit 在超过 MaxBytes 时删除最旧的已封存段
+func (s *Spool) enforceLimit() {
+	if s.cfg.MaxBytes <= 0 {
+		return
+	}
+	segs, err := s.segments()
+	if err != nil {
+		return
+	}
+	total := s.Pending()
+	for _, seq := range segs {
+		if total <= s.cfg.MaxBytes || s.isActive(seq) {
+			return
+		}
+		path := s.segmentPath(seq)
+		if fi, err := os.Stat(path); err == nil && os.Remove(path) == nil {
+			total -= fi.Size()
+			if s.cfg.OnError != nil {
+				s.cfg.OnError(fmt.Errorf("spool: over %d bytes, dropped %s", s.cfg.MaxBytes, filepath.Base(path)))
+			}
+		}
+	}
+}
+
+func (s *Spool) isActive(seq uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active != nil && seq == s.nextSeq-1
+}
+
+func (s *Spool) segmentPath(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolSuffix))
+}
+
+// segments 返回 dir 下按顺序排列的段序号
+func (s *Spool) segments() ([]uint64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segs []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, spoolSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segs = append(segs, seq)
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i] < segs[j] })
+	return segs, nil
+}
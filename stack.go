@@ -0,0 +1,67 @@
+package xslog
+
+import (
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackKey 是 LogConfig.StackTraceLevel 附加的堆栈属性名
+const StackKey = "stack"
+
+// 堆栈中属于日志库本身的帧，附加到记录时跳过
+const (
+	pkgPath     = "github.com/xbfding/xslog"
+	slogPkgPath = "log/slog"
+)
+
+// stackValue 在 JSON 中输出为字符串数组，控制台中输出为缩进的多行块
+type stackValue []string
+
+func (s stackValue) LogValue() slog.Value {
+	return slog.AnyValue([]string(s))
+}
+
+// captureStack 返回调用栈，每帧一行 "函数 文件:行号"；
+// skip 为 0 时从 captureStack 的调用方开始
+func captureStack(skip int) stackValue {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack stackValue
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame.Function+" "+frame.File+":"+strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// callerStack 返回去掉开头 xslog 和 log/slog 内部帧之后的调用栈，
+// 无论记录来自 Logger 的方法还是 slog.Logger，都从业务代码开始
+func callerStack() stackValue {
+	stack := captureStack(1)
+	for i, frame := range stack {
+		if !isLoggingFrame(frame) {
+			return stack[i:]
+		}
+	}
+	return stack
+}
+
+func isLoggingFrame(frame string) bool {
+	return strings.HasPrefix(frame, pkgPath+".") || strings.HasPrefix(frame, pkgPath+"/") ||
+		strings.HasPrefix(frame, slogPkgPath+".")
+}
+
+// Stack 返回调用处的堆栈属性（stack），skip 为 0 时从 Stack 的调用方开始；
+// 控制台中显示为缩进的多行块，适合中间件在 recover 中记录 panic
+func Stack(skip int) slog.Attr {
+	return slog.Any(StackKey, captureStack(skip+1))
+}
@@ -0,0 +1,105 @@
+package xslog
+
+import (
+	"expvar"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats.Dropped 中丢弃记录的原因
+const (
+	DropSampled     = "sampled"      // 被采样丢弃
+	DropRateLimited = "rate_limited" // 被限流丢弃
+	DropErrorStorm  = "error_storm"  // 错误风暴中被抑制
+	DropQueueFull   = "queue_full"   // 异步队列满时按 Overflow 策略丢弃
+)
+
+// Stats 是 Logger.Stats 返回的计数快照，从 logger 创建开始累计，级别使用完整名称，如 "INFO"
+type Stats struct {
+	Records map[string]uint64    `json:"records"` // 按级别统计通过过滤、交给输出的记录数
+	Dropped map[string]uint64    `json:"dropped"` // 按原因统计丢弃的记录数，见 DropSampled 等
+	Sinks   map[string]SinkStats `json:"sinks"`   // 按输出名称统计，如 "console"、"file" 或 Output.Name
+}
+
+// SinkStats 是一个输出的计数
+type SinkStats struct {
+	Written map[string]uint64 `json:"written"` // 按级别统计成功写入的记录数
+	Errors  uint64            `json:"errors"`  // 写入失败的次数，包括缓冲写出等不对应单条记录的失败
+}
+
+// logStats 保存 Stats 的计数，由父子 logger 共享
+type logStats struct {
+	records     counterMap[slog.Level]
+	written     counterMap[sinkLevel]
+	errors      counterMap[string]
+	sampled     atomic.Uint64
+	rateLimited atomic.Uint64
+	stormed     atomic.Uint64
+}
+
+type sinkLevel struct {
+	sink  string
+	level slog.Level
+}
+
+// counterMap 是按键分开的计数器，键第一次出现时创建
+type counterMap[K comparable] struct {
+	m sync.Map // K → *atomic.Uint64
+}
+
+func (c *counterMap[K]) add(key K) {
+	v, ok := c.m.Load(key)
+	if !ok {
+		v, _ = c.m.LoadOrStore(key, new(atomic.Uint64))
+	}
+	v.(*atomic.Uint64).Add(1)
+}
+
+func (c *counterMap[K]) each(fn func(key K, n uint64)) {
+	c.m.Range(func(k, v any) bool {
+		fn(k.(K), v.(*atomic.Uint64).Load())
+		return true
+	})
+}
+
+// Stats 返回记录数、丢弃数和各输出的写入计数
+func (ml *Logger) Stats() Stats {
+	st := Stats{
+		Records: map[string]uint64{},
+		Dropped: map[string]uint64{
+			DropSampled:     ml.stats.sampled.Load(),
+			DropRateLimited: ml.stats.rateLimited.Load(),
+			DropErrorStorm:  ml.stats.stormed.Load(),
+			DropQueueFull:   ml.Dropped(),
+		},
+		Sinks: map[string]SinkStats{},
+	}
+	ml.stats.records.each(func(level slog.Level, n uint64) {
+		st.Records[levelName(level)] += n
+	})
+	sink := func(name string) SinkStats {
+		s, ok := st.Sinks[name]
+		if !ok {
+			s.Written = map[string]uint64{}
+		}
+		return s
+	}
+	ml.stats.written.each(func(k sinkLevel, n uint64) {
+		s := sink(k.sink)
+		s.Written[levelName(k.level)] += n
+		st.Sinks[k.sink] = s
+	})
+	ml.stats.errors.each(func(name string, n uint64) {
+		s := sink(name)
+		s.Errors += n
+		st.Sinks[name] = s
+	})
+	return st
+}
+
+// PublishExpvar 以 name 把 Stats 发布到 expvar，可以在 /debug/vars 中查看。
+// 与 expvar.Publish 一样，同一个 name 只能发布一次，重复时 panic
+func (ml *Logger) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return ml.Stats() }))
+}
@@ -0,0 +1,144 @@
+package xslog
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OccurrencesKey 是错误风暴期间放行的记录上附加的属性名，值为该错误迄今出现的总次数
+const OccurrencesKey = "occurrences"
+
+// ErrorStormConfig 的默认值
+const (
+	defaultStormBurst      = 3
+	defaultStormBackoff    = time.Second
+	defaultStormMaxBackoff = 10 * time.Minute
+	stormBackoffFactor     = 10
+)
+
+// ErrorStormConfig 配置错误风暴抑制：同一个错误（消息加错误类型和内容）
+// 先完整记录 Burst 次，之后依次间隔 Backoff、10 倍 Backoff……（最长 MaxBackoff）才再记录一次，
+// 放行的记录带上期间被丢弃的条数（suppressed）和总次数（occurrences）。
+// 安静超过 MaxBackoff 后重新计数，上一轮还没报告的丢弃条数附加在重新计数后的第一条记录上
+type ErrorStormConfig struct {
+	Level      slog.Leveler  // 只处理达到该级别的记录，默认 Error
+	Burst      int           // 默认 3
+	Backoff    time.Duration // 默认 1s
+	MaxBackoff time.Duration // 默认 10m
+}
+
+type stormGuard struct {
+	cfg ErrorStormConfig
+
+	mu     sync.Mutex
+	storms map[string]*storm
+}
+
+type storm struct {
+	total      int
+	suppressed int
+	backoff    time.Duration
+	next       time.Time // 退避期间下一次放行的时间
+	lastSeen   time.Time
+}
+
+func newStormGuard(cfg *ErrorStormConfig) *stormGuard {
+	if cfg == nil {
+		return nil
+	}
+	g := &stormGuard{cfg: *cfg, storms: map[string]*storm{}}
+	if g.cfg.Level == nil {
+		g.cfg.Level = slog.LevelError
+	}
+	if g.cfg.Burst <= 0 {
+		g.cfg.Burst = defaultStormBurst
+	}
+	if g.cfg.Backoff <= 0 {
+		g.cfg.Backoff = defaultStormBackoff
+	}
+	if g.cfg.MaxBackoff <= 0 {
+		g.cfg.MaxBackoff = defaultStormMaxBackoff
+	}
+	return g
+}
+
+// allow 报告记录是否放行。放行的记录此前有被丢弃的同一个错误时返回丢弃的条数，
+// 退避期间放行时还返回该错误迄今出现的总次数，否则 occurrences 为 0
+func (g *stormGuard) allow(r slog.Record) (keep bool, suppressed, occurrences int) {
+	if g == nil || r.Level < g.cfg.Level.Level() {
+		return true, 0, 0
+	}
+	key := stormKey(r)
+	now := r.Time
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := g.storms[key]
+	pending := 0
+	if s == nil || now.Sub(s.lastSeen) > g.cfg.MaxBackoff {
+		if s == nil && len(g.storms) >= maxRateLimitKeys {
+			g.evict(now)
+		}
+		if s != nil {
+			// 重新计数，上一轮退避期间丢弃的条数由这一条报告
+			pending = s.suppressed
+		}
+		s = &storm{}
+		g.storms[key] = s
+	}
+	s.total++
+	s.lastSeen = now
+	if s.total <= g.cfg.Burst {
+		return true, pending, 0
+	}
+	if s.backoff == 0 {
+		// 刚用完 Burst，开始退避
+		s.backoff = g.cfg.Backoff
+		s.next = now.Add(s.backoff)
+		s.suppressed++
+		return false, 0, 0
+	}
+	if now.Before(s.next) {
+		s.suppressed++
+		return false, 0, 0
+	}
+	suppressed = s.suppressed
+	s.suppressed = 0
+	s.backoff *= stormBackoffFactor
+	if s.backoff > g.cfg.MaxBackoff {
+		s.backoff = g.cfg.MaxBackoff
+	}
+	s.next = now.Add(s.backoff)
+	return true, suppressed, s.total
+}
+
+// evict 删除已经平息的风暴
+func (g *stormGuard) evict(now time.Time) {
+	for key, s := range g.storms {
+		if now.Sub(s.lastSeen) > g.cfg.MaxBackoff {
+			delete(g.storms, key)
+		}
+	}
+}
+
+// stormKey 是错误的指纹：消息加上记录中各个错误的类型和内容
+func stormKey(r slog.Record) string {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		switch v := a.Value.Any().(type) {
+		case errorValue:
+			b.WriteByte(0)
+			b.WriteString(v.errType())
+			b.WriteByte(0)
+			b.WriteString(v.err.Error())
+		case error:
+			b.WriteByte(0)
+			b.WriteString(v.Error())
+		}
+		return true
+	})
+	return b.String()
+}
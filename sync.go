@@ -0,0 +1,89 @@
+package xslog
+
+import (
+	"bytes"
+	"time"
+)
+
+// SyncPolicy 决定日志文件何时 fsync，在持久性和吞吐量之间取舍。
+// 零值从不主动 fsync，依赖操作系统的缓存策略（调用 Flush 时仍会刷盘）；
+// Every: 1 表示每条记录后都 fsync；两个字段都设置时任一条件满足就 fsync
+type SyncPolicy struct {
+	Every    int           // 每写入 Every 条记录 fsync 一次
+	Interval time.Duration // 有新写入时，每隔 Interval fsync 一次
+}
+
+// afterWrite 在写入 p 之后按 Every 检查是否需要 fsync，调用方持有 w.mu
+func (w *fileWriter) afterWrite(p []byte) error {
+	w.dirty = true
+	if w.policy.Every <= 0 {
+		return nil
+	}
+	w.unsynced += bytes.Count(p, []byte{'\n'})
+	if w.unsynced < w.policy.Every {
+		return nil
+	}
+	return w.syncLocked()
+}
+
+// syncLocked 写出缓冲并 fsync
+func (w *fileWriter) syncLocked() error {
+	w.unsynced = 0
+	w.dirty = false
+	if err := w.flushLocked(); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// start 按 SyncPolicy.Interval 和缓冲的写出间隔启动后台 goroutine，由 stopTicker 停止
+func (w *fileWriter) start() {
+	syncC, stopSync := tickerChan(w.policy.Interval)
+	flushC, stopFlush := tickerChan(w.flushInterval)
+	if syncC == nil && flushC == nil {
+		return
+	}
+	w.stop = make(chan struct{})
+	w.stopped = make(chan struct{})
+	go func() {
+		defer close(w.stopped)
+		defer stopSync()
+		defer stopFlush()
+		for {
+			select {
+			case <-syncC:
+				w.mu.Lock()
+				var err error
+				if w.file != nil && w.dirty {
+					err = w.syncLocked()
+				}
+				w.mu.Unlock()
+				w.report(err)
+			case <-flushC:
+				w.mu.Lock()
+				err := w.flushLocked()
+				w.mu.Unlock()
+				w.report(err)
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// tickerChan 在 d 大于 0 时返回 ticker 的通道，否则返回 nil 通道
+func tickerChan(d time.Duration) (<-chan time.Time, func()) {
+	if d <= 0 {
+		return nil, func() {}
+	}
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+func (w *fileWriter) stopTicker() {
+	if w.stop != nil {
+		close(w.stop)
+		<-w.stopped
+		w.stop = nil
+	}
+}
@@ -0,0 +1,52 @@
+package xslog
+
+import (
+	"bytes"
+	"sync/atomic"
+)
+
+// TB 是 NewTestLogger 用到的 testing.TB 方法，*testing.T 和 *testing.B 都满足
+type TB interface {
+	Helper()
+	Log(args ...any)
+	Fatal(args ...any)
+	Cleanup(func())
+}
+
+// NewTestLogger 返回把控制台格式（不带颜色）的日志通过 t.Log 输出的 logger，
+// 日志会和失败的测试显示在一起。默认记录 Trace 及以上级别、不读取环境变量，
+// opts 可以覆盖这些设置；测试结束时自动关闭 logger
+//
+//	func TestServer(t *testing.T) {
+//		logger := xslog.NewTestLogger(t)
+//		srv := NewServer(logger)
+//		...
+//	}
+func NewTestLogger(t TB, opts ...Option) *Logger {
+	t.Helper()
+	w := &testWriter{t: t}
+	base := []Option{WithoutEnv(), WithConsole(LevelTrace), WithConsoleWriter(w, true)}
+	ml, err := NewLoggerWithOptions(append(base, opts...)...)
+	if err != nil {
+		t.Fatal("xslog: create test logger: ", err)
+	}
+	t.Cleanup(func() {
+		_ = ml.Close()
+		// 测试结束后再调用 t.Log 会 panic，之后的记录直接丢弃
+		w.done.Store(true)
+	})
+	return ml
+}
+
+// testWriter 把控制台输出的每条记录交给 t.Log
+type testWriter struct {
+	t    TB
+	done atomic.Bool
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	if !w.done.Load() {
+		w.t.Log(string(bytes.TrimSuffix(p, []byte("\n"))))
+	}
+	return len(p), nil
+}
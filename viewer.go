@@ -0,0 +1,209 @@
+package xslog
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ViewerHandler 返回一个简单的网页日志查看器，适合没有日志平台的小型自托管应用。
+// 页面显示环形缓冲（LogConfig.RingBuffer）中的最近记录并通过 SSE 实时追加新记录，
+// 可以按级别过滤和搜索消息；选择“文件”时从日志文件（包括轮转出的文件，见 OpenLogFiles）中查询。
+// 以目录的形式挂载，路径需要以 "/" 结尾：
+//
+//	mux.Handle("/debug/logs/", http.StripPrefix("/debug/logs", logger.ViewerHandler()))
+//
+// 除页面本身外还提供两个接口，参数与 RecentHandler 相同：
+//
+//	records  JSON 数组，source=file 时查询日志文件
+//	events   新记录的 SSE 流，每条记录一个 JSON 对象
+//
+// 记录中可能含有敏感信息，挂载时应自行做好访问控制
+func (ml *Logger) ViewerHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/records"):
+			ml.serveRecords(w, r)
+		case strings.HasSuffix(r.URL.Path, "/events"):
+			ml.serveEvents(w, r)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(viewerPage))
+		}
+	})
+}
+
+func (ml *Logger) serveRecords(w http.ResponseWriter, r *http.Request) {
+	q, err := parseRecordQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var records ObservedRecords
+	switch source := r.URL.Query().Get("source"); source {
+	case "", "ring":
+		if ml.ring == nil {
+			http.Error(w, "ring buffer not configured", http.StatusNotFound)
+			return
+		}
+		records = ml.ring.Records().Query(q)
+	case "file":
+		ml.mu.Lock()
+		path := ml.config.LogFilePath
+		ml.mu.Unlock()
+		if path == "" {
+			http.Error(w, "log file not configured", http.StatusNotFound)
+			return
+		}
+		entries, err := QueryLogFiles(path, q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, e := range entries {
+			records = append(records, e.ObservedRecord)
+		}
+	default:
+		http.Error(w, "source: expected ring or file, got "+source, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(appendRecordsJSON(nil, records))
+}
+
+// SSE 连接上没有新记录时发送注释保持连接的间隔
+const viewerKeepAlive = 30 * time.Second
+
+func (ml *Logger) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if ml.ring == nil {
+		http.Error(w, "ring buffer not configured", http.StatusNotFound)
+		return
+	}
+	q, err := parseRecordQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Since = time.Time{}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	ch, cancel := ml.ring.store.subscribe(256)
+	defer cancel()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	ticker := time.NewTicker(viewerKeepAlive)
+	defer ticker.Stop()
+	var buf []byte
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			buf = append(buf[:0], ": keep-alive\n\n"...)
+		case rec := <-ch:
+			if !q.Match(rec) {
+				continue
+			}
+			b, _ := MarshalRecord(rec.record())
+			buf = append(append(append(buf[:0], "data: "...), b...), "\n\n"...)
+		}
+		if _, err := w.Write(buf); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+const viewerPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>xslog</title>
+<style>
+body { margin: 0; font: 13px/1.5 ui-monospace, Menlo, Consolas, monospace; background: #1e1e1e; color: #ddd; }
+header { position: sticky; top: 0; display: flex; gap: 8px; padding: 8px; background: #2d2d2d; }
+input, select, button { font: inherit; background: #1e1e1e; color: #ddd; border: 1px solid #555; padding: 2px 6px; }
+#q { flex: 1; }
+#log div { padding: 0 8px; white-space: pre-wrap; word-break: break-all; }
+.t { color: #888; } .a { color: #9cdcfe; }
+.TRACE, .DEBUG { color: #c586c0; } .INFO { color: #569cd6; } .NOTICE { color: #4ec9b0; }
+.WARN { color: #dcdcaa; } .ERROR, .FATAL { color: #f44747; }
+</style>
+</head>
+<body>
+<header>
+<select id="source"><option value="ring">recent</option><option value="file">file</option></select>
+<select id="level">
+<option value="">all levels</option><option>debug</option><option>info</option><option>warn</option><option>error</option>
+</select>
+<input id="q" placeholder="search messages">
+<label><input type="checkbox" id="live" checked> live</label>
+<button id="clear">clear</button>
+</header>
+<div id="log"></div>
+<script>
+const log = document.getElementById("log");
+const $ = id => document.getElementById(id);
+let es;
+
+function params() {
+  const p = new URLSearchParams({n: "1000", source: $("source").value});
+  if ($("level").value) p.set("level", $("level").value);
+  if ($("q").value) p.set("q", $("q").value);
+  return p;
+}
+
+function add(rec) {
+  const {time, level, msg, ...attrs} = rec;
+  const div = document.createElement("div");
+  const span = (cls, text) => {
+    const s = document.createElement("span");
+    s.className = cls;
+    s.textContent = text;
+    div.appendChild(s);
+  };
+  span("t", time + " ");
+  span(String(level).replace(/[+-].*/, ""), level + " ");
+  span("", msg);
+  for (const [k, v] of Object.entries(attrs)) {
+    span("a", " " + k + "=");
+    span("", typeof v === "string" ? v : JSON.stringify(v));
+  }
+  const bottom = window.innerHeight + window.scrollY >= document.body.scrollHeight - 4;
+  log.appendChild(div);
+  while (log.childElementCount > 5000) log.firstChild.remove();
+  if (bottom) window.scrollTo(0, document.body.scrollHeight);
+}
+
+async function reload() {
+  if (es) { es.close(); es = null; }
+  log.textContent = "";
+  const resp = await fetch("records?" + params());
+  if (!resp.ok) { log.textContent = await resp.text(); return; }
+  (await resp.json()).forEach(add);
+  window.scrollTo(0, document.body.scrollHeight);
+  if ($("live").checked && $("source").value === "ring") {
+    es = new EventSource("events?" + params());
+    es.onmessage = e => add(JSON.parse(e.data));
+  }
+}
+
+let timer;
+$("q").oninput = () => { clearTimeout(timer); timer = setTimeout(reload, 300); };
+$("level").onchange = $("source").onchange = $("live").onchange = reload;
+$("clear").onclick = () => { log.textContent = ""; };
+reload();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,141 @@
+package xslog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// 单行的最大长度，超过时不等换行直接记录
+const maxWriterLine = 64 << 10
+
+// Writer 返回按行记录写入内容的 io.Writer，用于捕获 exec.Cmd 的输出和只能写 io.Writer 的旧代码：
+//
+//	cmd.Stderr = logger.Named("ffmpeg").Writer(slog.LevelWarn)
+//
+// 行首是常见的级别前缀（如 "ERROR:"、"[warn]"、"INFO "）时按前缀的级别记录并去掉前缀，
+// 否则按 defaultLevel 记录；空行忽略。最后一行没有换行时，调用 Close 记录它
+func (ml *Logger) Writer(defaultLevel slog.Level) io.WriteCloser {
+	return &lineWriter{ml: ml, level: defaultLevel}
+}
+
+type lineWriter struct {
+	ml    *Logger
+	level slog.Level
+
+	mu  sync.Mutex
+	buf []byte // 还没有遇到换行的内容
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= maxWriterLine {
+				w.emit(w.buf)
+				w.buf = w.buf[:0]
+			}
+			break
+		}
+		if len(w.buf) > 0 {
+			w.buf = append(w.buf, p[:i]...)
+			w.emit(w.buf)
+			w.buf = w.buf[:0]
+		} else {
+			w.emit(p[:i])
+		}
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Close 记录最后一行不完整的内容，之后仍然可以继续写入
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = w.buf[:0]
+	}
+	return nil
+}
+
+func (w *lineWriter) emit(line []byte) {
+	msg := strings.TrimRight(string(line), "\r \t")
+	if msg == "" {
+		return
+	}
+	level, msg := detectLevelPrefix(msg, w.level)
+	ctx := context.Background()
+	if !w.ml.enabled(ctx, level) {
+		return
+	}
+	// 写入方与日志调用处无关，不记录调用位置
+	_ = w.ml.handle(ctx, slog.NewRecord(w.ml.now(), level, msg, 0))
+}
+
+// detectLevelPrefix 识别行首的级别前缀，支持 "ERROR:"、"error "、"[warn]"、"<info>"、"W:" 等写法，
+// 级别名可以是注册过的名称或缩写，也可以是 "WARNING"、"ERR"、"CRIT" 这类常见别名
+func detectLevelPrefix(line string, def slog.Level) (slog.Level, string) {
+	word, rest, ok := splitLevelPrefix(line)
+	if !ok {
+		return def, line
+	}
+	if level, ok := prefixLevels[strings.ToLower(word)]; ok {
+		return level, rest
+	}
+	if level, err := ParseLevel(word); err == nil && !isNumeric(word) {
+		return level, rest
+	}
+	return def, line
+}
+
+// prefixLevels 是 ParseLevel 之外常见的级别写法
+var prefixLevels = map[string]slog.Level{
+	"warning":  slog.LevelWarn,
+	"err":      slog.LevelError,
+	"crit":     LevelFatal,
+	"critical": LevelFatal,
+	"fatal":    LevelFatal,
+	"panic":    LevelFatal,
+	"dbg":      slog.LevelDebug,
+	"d":        slog.LevelDebug,
+	"i":        slog.LevelInfo,
+	"w":        slog.LevelWarn,
+	"e":        slog.LevelError,
+}
+
+// splitLevelPrefix 取出行首可能是级别的单词：括号包围的（"[warn] x"、"<info> x"），
+// 或以冒号、空格结尾的（"ERROR: x"、"WARN x"）；以空格结尾时要求是大写，避免把普通单词当成级别
+func splitLevelPrefix(line string) (word, rest string, ok bool) {
+	if len(line) > 1 && (line[0] == '[' || line[0] == '<') {
+		closer := byte(']')
+		if line[0] == '<' {
+			closer = '>'
+		}
+		if i := strings.IndexByte(line, closer); i > 1 && i <= 10 {
+			return line[1:i], strings.TrimLeft(line[i+1:], " :\t"), true
+		}
+		return "", "", false
+	}
+	i := strings.IndexAny(line, ": \t")
+	if i <= 0 || i > 9 {
+		return "", "", false
+	}
+	word = line[:i]
+	if line[i] != ':' && word != strings.ToUpper(word) {
+		return "", "", false
+	}
+	return word, strings.TrimLeft(line[i:], " :\t"), true
+}
+
+func isNumeric(s string) bool {
+	return strings.Trim(s, "+-0123456789") == ""
+}
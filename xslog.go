@@ -1,14 +1,20 @@
 package xslog
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/ed25519"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type LogConfig struct {
@@ -17,142 +23,558 @@ type LogConfig struct {
 	LogFilePath     string
 	LevelForFile    slog.Level
 	LevelForConsole slog.Level
+	IgnoreEnv       bool // 为 true 时不读取 LOG_LEVEL 等环境变量
+
+	// ConsoleWriter 是控制台输出的目标，为 nil 时为 os.Stdout；
+	// ConsoleNoColor 为 true 时不输出 ANSI 颜色，适合写到文件或测试日志
+	ConsoleWriter  io.Writer
+	ConsoleNoColor bool
+
+	// ConsoleFlattenGroups 为 true 时控制台把分组中的每个属性显示为 parent.child.key=value，
+	// 默认显示为 parent=[child=[key=value]]，见 TxtColoredHandler.WithFlattenGroups
+	ConsoleFlattenGroups bool
+
+	// ConsoleLevelStyle 决定控制台中级别标签的写法，默认为三个字母的缩写（如 "INF"）；
+	// ConsoleLevelLabels 中的级别改用给出的标签，见 TxtColoredHandler.WithLevelLabels
+	ConsoleLevelStyle  LevelStyle
+	ConsoleLevelLabels map[slog.Level]string
+
+	// ConsoleTheme 是控制台的配色，零值为默认配色，见 Theme
+	ConsoleTheme Theme
+
+	// ConsoleLineColor 为 true 时控制台整行按级别着色，而不只是级别标签，见 TxtColoredHandler.WithLineColor
+	ConsoleLineColor bool
+
+	// ConsoleSortAttrs 为 true 时控制台按键名排序顶层属性，默认按添加顺序；
+	// ConsolePinnedKeys 中的属性（如 request_id、trace_id）总是按给出的顺序排在最前面。
+	// 只影响控制台，日志文件等 JSON 输出保持添加顺序
+	ConsoleSortAttrs  bool
+	ConsolePinnedKeys []string
+
+	// Async 配置异步输出，默认同步写出
+	Async AsyncConfig
+
+	// FileSync 决定日志文件何时 fsync，默认交给操作系统
+	FileSync SyncPolicy
+
+	// FileBufferSize 大于 0 时，日志文件先写入这么大的内存缓冲，
+	// 缓冲写满或每隔 FileFlushInterval（默认 1s）写到文件，Flush/Close 时也会写出，
+	// 进程崩溃时最多丢失一个间隔内的记录
+	FileBufferSize    int
+	FileFlushInterval time.Duration
+
+	// StackTraceLevel 不为 nil 时，达到该级别的记录会附加调用栈（stack 属性），
+	// 例如 StackTraceLevel: slog.LevelError
+	StackTraceLevel slog.Leveler
+
+	// Outputs 是日志文件之外的 JSON 输出，与日志文件共用一次编码
+	Outputs []Output
+
+	// InternalLog 是 xslog 自身问题（没有 OnError 回调时的写入失败、磁盘写满、异步队列丢弃记录等）的输出，
+	// 默认为 os.Stderr，设为 io.Discard 可以关闭；这些消息不经过 Logger，同一类每 10 秒最多输出一次
+	InternalLog io.Writer
+
+	// Fallback 不为 nil 时（如 os.Stderr），任一输出连续写入失败 FallbackAfter 次（默认 3）后，
+	// 写不进去的记录改写到 Fallback，避免磁盘写满时应用完全没有日志；
+	// 日志文件所在磁盘写满（见 DiskFullRetry）时，暂停期间的记录立即改写到 Fallback
+	Fallback      io.Writer
+	FallbackAfter int
+
+	// Rotation 不为 nil 时日志文件写到一定大小后轮转，并按数量和时间清理旧文件，见 RotationConfig
+	Rotation *RotationConfig
+
+	// DiskFullRetry 是磁盘写满后暂停文件输出的时长（默认 30s），期满后重新打开文件重试；
+	// 暂停期间的记录写到 Fallback（没有配置时丢弃），并每 10 秒在 InternalLog 中提醒一次
+	DiskFullRetry time.Duration
+
+	// Sampling 不为 nil 时按级别和消息采样，见 SamplingConfig
+	Sampling *SamplingConfig
+
+	// RateLimit 不为 nil 时按消息或属性值限流，见 RateLimitConfig
+	RateLimit *RateLimitConfig
+
+	// Redact 是需要脱敏的属性键名模式，不区分大小写，支持通配符，
+	// 如 []string{"password", "*token*", "*_secret"}；匹配的值在任何输出看到之前替换为 "[REDACTED]"，
+	// 分组和 map 中的键同样处理
+	Redact []string
+
+	// FileFormat 是日志文件的编码格式，默认为 JSON，见 FormatECS 等
+	FileFormat Format
+
+	// PIIConsole 和 PIIFile 决定控制台和日志文件如何处理 PII 标记的属性，默认都是 PIIRedact
+	PIIConsole PIIPolicy
+	PIIFile    PIIPolicy
+
+	// Scrub 是按正则改写消息和字符串属性值的规则，用于捕获键名看不出来的泄露，
+	// 如 ScrubCreditCard、ScrubBearerToken、ScrubEmail；在 Redact 之后执行
+	Scrub []ScrubRule
+
+	// ErrorStorm 不为 nil 时，反复出现的同一个错误按指数退避减少记录次数，见 ErrorStormConfig
+	ErrorStorm *ErrorStormConfig
+
+	// Dedupe 大于 0 时，从第一条记录起的这个时间窗口内连续重复的记录只输出第一条，
+	// 之后输出一条带 repeated 属性（重复次数）的汇总
+	Dedupe time.Duration
+
+	// FileHashChain 为 true 时，日志文件的每条记录都带上一条记录的 SHA-256（prev_hash），
+	// 可以用 VerifyChain 检查文件是否被改动过。追加到已有文件时接着其中最后一条记录继续。
+	// 它和 FileSigningKey 都只能用于 JSON 格式的 FileFormat（默认、FormatECS 和 GCPFormat），
+	// 与 CEF、LEEF、访问日志等格式同时使用时 NewLogger 返回错误
+	FileHashChain bool
+
+	// FileSigningKey 不为 nil 时，日志文件的每条记录末尾带 Ed25519 签名（sig），
+	// 可以用 VerifyRecord 或 VerifySignatures 校验；与 FileHashChain 同时使用时签名覆盖 prev_hash。
+	// 密钥可以用 LoadSigningKey 从文件读取
+	FileSigningKey ed25519.PrivateKey
+
+	// Audit 不为 nil 时，Logger.Audit 的记录写到这个独立的输出，见 AuditConfig
+	Audit *AuditConfig
+
+	// Limits 不为 nil 时截断过长的消息、属性值和过多的属性，见 SizeLimits
+	Limits *SizeLimits
+
+	// Handlers 是额外的 slog.Handler（如 ObserverSink），与控制台和日志文件一样
+	// 收到经过采样、脱敏、截断等处理之后的记录，按各自的 Enabled 过滤级别，同步调用
+	Handlers []slog.Handler
+
+	// ErrorCauses 为 true 时，Err 和 ErrWithStack 的错误除了 error 和 error_type 之外，
+	// 还把 errors.Unwrap 和 errors.Join 包装的整条错误链展开为 error_causes 数组，
+	// 每个原因是 {"error": 信息, "error_type": 类型}，按深度优先顺序排列，最多 32 个；
+	// 控制台在日志行之后逐行显示 "caused by: 信息 (类型)"
+	ErrorCauses bool
+
+	// Sequence 为 true 时每条记录带上从 1 开始递增的序号（seq），同一个 logger 及其派生的子 logger
+	// 共用一个计数器，下游可以据此发现经过异步队列、网络转发后顺序错乱或丢失的记录。
+	// 序号在采样、限流之后分配，被它们丢弃的记录不占序号；Dedupe 合并掉的重复记录会留下空缺
+	Sequence bool
+
+	// MonotonicTime 为 true 时每条记录除了墙上时间（time）之外，还带上按单调时钟计算的
+	// 距进程启动的时长（mono，JSON 中为纳秒数）。NTP 校时会让墙上时间跳变，
+	// 分析日志文件中的延迟时用 mono 相减不会得到负数或错误的间隔。
+	// 配置了 Clock 时按 Clock 返回的时间计算，没有单调时钟读数时退化为墙上时间之差
+	MonotonicTime bool
+
+	// GoroutineID 为 true 时每条记录带上写日志的 goroutine 编号（goroutine），
+	// 用于在没有请求 ID 的日志中区分交错的并发操作。取编号需要调用 runtime.Stack，
+	// 开销比普通属性大得多，只应在排查问题时开启，开启时会在 InternalLog 输出一条提示
+	GoroutineID bool
+
+	// Attrs 是附加到每条记录的固定属性，如部署环境、区域、服务名，按键名排序，
+	// 与 Logger.With 的属性一样经过脱敏，写到所有输出
+	Attrs map[string]any
+
+	// ProcessInfo 为 true 时每条记录都带上主机名（host）、进程号（pid）和可执行文件名（exe），
+	// 这些值在创建 logger 时计算一次
+	ProcessInfo bool
+
+	// BuildInfo 决定是否记录 debug.ReadBuildInfo 中的版本（version）、VCS 修订号（revision）
+	// 和 Go 版本（go_version）：附加到每条记录，或者只在创建 logger 时记录一条，见 BuildInfoMode
+	BuildInfo BuildInfoMode
+
+	// Clock 不为 nil 时代替 time.Now 给记录打时间，包括经过 Handler 和 StdLogger 的记录；
+	// 采样、限流、合并重复记录等按时间工作的功能也随之使用这个时钟，
+	// 用于测试和 golden file 比较得到固定的输出
+	Clock func() time.Time
+
+	// RingBuffer 大于 0 时在内存中保留最近这么多条记录，不论级别，可以用 Logger.Recent 取出；
+	// 这时所有级别的日志都会经过处理流程，Debug 等低级别日志的开销与实际输出时相近
+	RingBuffer int
 }
 
+// LoggerNameKey 是 Named 设置的名称在日志记录中的属性名
+const LoggerNameKey = "logger"
+
 type Logger struct {
-	consoleLogger   *slog.Logger
-	fileLogger      *slog.Logger
+	*loggerCore
+	handler *MultiHandler // 控制台和文件输出
+	name    string        // Named 设置的层级名称，如 "db.pool"
+}
+
+// loggerCore 保存父子 logger 之间共享的状态
+type loggerCore struct {
+	mu              sync.Mutex // 保护 config 的修改和输出的切换
 	config          LogConfig
 	consoleLevelVar *slog.LevelVar // 用于动态控制控制台日志级别
 	fileLevelVar    *slog.LevelVar // 用于动态控制文件日志级别
-	fileWriter      io.Writer      // 保存文件写入器，方便后续操作
+	fileWriter      *fileWriter    // 保存文件写入器，方便后续操作
+	auditWriter     *fileWriter    // 审计文件，没有配置时为 nil
+	consoleOn       atomic.Bool    // 控制台输出开关，与 config.LogToConsole 同步
+	fileOn          atomic.Bool    // 文件输出开关，与 config.LogToFile 同步
+	levelRules      levelRules     // SetLevelFor 设置的按名称覆盖级别的规则
+	configPath      string         // NewLoggerFromFile 使用的配置文件，供 Reload 使用
+	configRaw       map[string]any // 创建 logger 时配置文件的内容，Reload 据此发现不能重载的改动
+	workers         []*asyncWorker // 异步模式下每个输出的后台队列
+	shutdownTimeout atomic.Int64   // Close 等待异步队列的最长时间
+	sinkErrors      errorReporter  // 输出的写入错误
+	stats           logStats       // Logger.Stats 的计数
+	self            *selfLog       // xslog 自身问题的输出
+	deadLetters     []*deadLetterWriter
+	sampler         *sampler         // 为 nil 时不采样
+	limiter         *rateLimiter     // 为 nil 时不限流
+	deduper         *deduper         // 为 nil 时不合并重复记录
+	storms          *stormGuard      // 为 nil 时不抑制错误风暴
+	sizes           *sizeLimiter     // 为 nil 时不限制记录大小
+	ring            *ObserverSink    // LogConfig.RingBuffer 的环形缓冲，未配置时为 nil
+	clock           func() time.Time // LogConfig.Clock，为 nil 时为 time.Now
+	transforms      []attrTransform  // 脱敏等属性改写，按顺序执行
+	onceKeys        onceKeys         // InfoOnce 等方法已经输出过的键
+	seq             atomic.Uint64    // LogConfig.Sequence 的序号
+
+	// AddContextExtractor 注册的提取器，写时复制，注册与记录日志可以并发
+	extractors atomic.Pointer[[]ContextExtractor]
 }
 
-type TxtColoredHandler struct {
-	out  io.Writer
-	opts *slog.HandlerOptions
+// FileFlushInterval 的默认值
+const defaultFileFlushInterval = time.Second
+
+// fileWriter 可以替换底层文件的写入器，
+// 更换日志文件时已有的 handler（包括子 logger 的）无需重建
+type fileWriter struct {
 	mu   sync.Mutex
+	file *os.File
+
+	policy   SyncPolicy
+	unsynced int  // 上次 fsync 之后写入的记录数
+	dirty    bool // 上次 fsync 之后是否有写入
+
+	buf           *bufio.Writer // 为 nil 时直接写文件
+	flushInterval time.Duration
+	report        func(error) // 报告后台写出和 fsync 的错误
+	self          *selfLog    // 磁盘写满后恢复等提示的输出
+
+	// 磁盘写满（ENOSPC）后暂停文件输出，到 retryAt 再重试
+	retry          time.Duration
+	suspended      bool
+	resuming       bool // 重新打开之后还没有写入成功
+	retryAt        time.Time
+	suspendedDrops int       // 暂停期间没有写入日志文件的记录数
+	fallback       io.Writer // LogConfig.Fallback，暂停期间的记录写到这里，为 nil 时丢弃
+
+	chain  *hashChain    // 为 nil 时不加哈希链
+	signer *recordSigner // 为 nil 时不签名
+	framed []byte        // frame 的结果
+
+	rotation *RotationConfig // 为 nil 时不轮转
+	size     int64           // 当前文件已写入的字节数，包括缓冲中还没写出的
+
+	stop    chan struct{}
+	stopped chan struct{}
 }
 
-func NewTxtColoredHandler(out io.Writer, opts *slog.HandlerOptions) *TxtColoredHandler {
-	if opts == nil {
-		opts = &slog.HandlerOptions{}
+func newFileWriter(config LogConfig) *fileWriter {
+	w := &fileWriter{policy: config.FileSync, retry: config.DiskFullRetry}
+	if w.retry <= 0 {
+		w.retry = defaultDiskFullRetry
+	}
+	if config.FileHashChain {
+		w.chain = &hashChain{}
 	}
-	return &TxtColoredHandler{
-		opts: opts,
-		out:  out,
+	if config.FileSigningKey != nil {
+		w.signer = &recordSigner{key: config.FileSigningKey}
 	}
+	if config.Rotation != nil {
+		rotation := *config.Rotation
+		w.rotation = &rotation
+	}
+	if config.FileBufferSize > 0 {
+		w.buf = bufio.NewWriterSize(nil, config.FileBufferSize)
+		w.flushInterval = config.FileFlushInterval
+		if w.flushInterval <= 0 {
+			w.flushInterval = defaultFileFlushInterval
+		}
+	}
+	return w
 }
 
-func (h *TxtColoredHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	// 如果没有设置 Level，则默认启用所有级别
-	if h.opts.Level == nil {
-		return true
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return len(p), nil
+	}
+	if w.suspended && !w.resume() {
+		w.divert(p)
+		return len(p), nil
+	}
+	if w.shouldRotate(len(p)) {
+		w.rotate()
+	}
+	var prev []byte
+	if w.chain != nil {
+		prev = w.chain.prev
+	}
+	line := w.frame(p)
+	var err error
+	if w.buf != nil {
+		_, err = w.buf.Write(line)
+	} else {
+		_, err = w.file.Write(line)
+	}
+	if err == nil {
+		w.size += int64(len(line))
+		err = w.afterWrite(p)
+	} else if w.chain != nil {
+		// 没有写进去的记录不算在链上
+		w.chain.prev = prev
+	}
+	if w.handleDiskFull(err) {
+		w.divert(p)
+		return len(p), nil
+	}
+	if err == nil && w.buf == nil {
+		w.resumed()
 	}
-	// 检查当前级别是否符合配置的级别
-	return level >= h.opts.Level.Level()
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
 }
 
-func (h *TxtColoredHandler) Handle(ctx context.Context, r slog.Record) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	levelStr := fmt.Sprintf("\x1b[%dm%s\x1b[0m", getLevelColor(r.Level), getLevelName(r))
-	//levelStr := fmt.Sprintf("\x1b[1;%dm%s\x1b[0m", levelColor, strings.ToUpper(r.Level.String()))
-
-	//timeStr := r.Time.Format("2006-01-02 15:04:05")
-	//msg := fmt.Sprintf("%s [%s] %s", timeStr, levelStr, r.Message)
-	msg := fmt.Sprintf("[%s] %s", levelStr, r.Message)
-
-	var attrs []string
-	r.Attrs(func(a slog.Attr) bool {
-		attrs = append(attrs, fmt.Sprintf("%v", a.Value.Any()))
-		return true
-	})
-
-	if len(attrs) > 0 {
-		msg += " " + strings.Join(attrs, " ")
+// frame 返回加上哈希链和签名之后要写入文件的内容。批量写入时 p 包含多条以换行结尾的记录，
+// 每条记录各自链接和签名；返回的切片在下一次调用前有效，调用方持有 w.mu
+func (w *fileWriter) frame(p []byte) []byte {
+	if w.chain == nil && w.signer == nil {
+		return p
 	}
-
-	_, err := fmt.Fprintln(h.out, msg)
-	return err
+	w.framed = w.framed[:0]
+	for len(p) > 0 {
+		record := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			record = p[:i+1]
+		}
+		p = p[len(record):]
+		line := record
+		if w.chain != nil {
+			line = w.chain.link(line)
+		}
+		if w.signer != nil {
+			line = w.signer.sign(line)
+		}
+		if w.chain != nil {
+			w.chain.commit(line)
+		}
+		w.framed = append(w.framed, line...)
+	}
+	framed := w.framed
+	if cap(framed) > maxPooledBuffer {
+		// 不长期持有特别大的批量
+		w.framed = nil
+	}
+	return framed
 }
 
-func (h *TxtColoredHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return h
+// flushLocked 把缓冲中的内容写到文件，调用方持有 w.mu
+func (w *fileWriter) flushLocked() error {
+	if w.buf == nil || w.file == nil || w.suspended {
+		return nil
+	}
+	err := w.buf.Flush()
+	if w.handleDiskFull(err) {
+		return nil
+	}
+	if err == nil {
+		w.resumed()
+	}
+	return err
 }
 
-func (h *TxtColoredHandler) WithGroup(name string) slog.Handler {
-	return h
+// swap 替换底层文件，返回旧文件；缓冲中的内容先写入旧文件
+func (w *fileWriter) swap(file *os.File) *os.File {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.flushLocked()
+	old := w.file
+	w.file = file
+	if w.buf != nil {
+		w.buf.Reset(file)
+	}
+	w.unsynced, w.dirty = 0, false
+	w.suspended, w.resuming, w.suspendedDrops = false, false, 0
+	w.size = 0
+	if file != nil {
+		if fi, err := file.Stat(); err == nil {
+			w.size = fi.Size()
+		}
+	}
+	if w.chain != nil && file != nil {
+		// 接着文件中已有的记录继续，重启或切换回旧文件后链不会断
+		if err := w.chain.resume(file.Name()); err != nil && w.report != nil {
+			w.report(err)
+		}
+	}
+	return old
 }
 
-func getLevelColor(level slog.Level) int {
-	switch level {
-	case slog.LevelDebug:
-		return 35 // Purple
-	case slog.LevelInfo:
-		return 34 // Blue
-	case slog.LevelWarn:
-		return 33 // Yellow
-	case slog.LevelError:
-		return 31 // Red
-	default:
-		return 37 // Default White
+// Sync 把已写入的内容刷到磁盘
+func (w *fileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil || w.suspended {
+		return nil
 	}
+	return w.syncLocked()
 }
 
-func getLevelName(r slog.Record) string {
-	switch r.Level {
-	case slog.LevelDebug:
-		return "DBG"
-	case slog.LevelInfo:
-		return "INF"
-	case slog.LevelWarn:
-		return "WRN"
-	case slog.LevelError:
-		return "ERR"
-	default:
-		return strings.ToUpper(r.Level.String()[:3])
+func (w *fileWriter) Close() error {
+	w.mu.Lock()
+	err := w.flushLocked()
+	w.mu.Unlock()
+	if old := w.swap(nil); old != nil {
+		return errors.Join(err, old.Close())
 	}
+	return err
 }
 
 func NewLogger(config LogConfig) (*Logger, error) {
+	// 环境变量中的级别优先于代码中的配置，方便运维直接调整
+	if err := applyEnvLevels(&config); err != nil {
+		return nil, err
+	}
+
+	// 哈希链和签名把字段插入 JSON 对象，CEF、LEEF 和访问日志等文本格式无法使用
+	if (config.FileHashChain || config.FileSigningKey != nil) && !isJSONFormat(config.FileFormat) {
+		return nil, errors.New("FileHashChain and FileSigningKey require a JSON FileFormat")
+	}
+
+	// 复制一份再补默认值，不修改调用方的切片
+	config.Outputs = append([]Output(nil), config.Outputs...)
+	for i := range config.Outputs {
+		if config.Outputs[i].Name == "" {
+			config.Outputs[i].Name = "output"
+		}
+	}
+
 	ml := &Logger{
-		config:          config,
-		consoleLevelVar: new(slog.LevelVar),
-		fileLevelVar:    new(slog.LevelVar),
+		loggerCore: &loggerCore{
+			config:          config,
+			consoleLevelVar: new(slog.LevelVar),
+			fileLevelVar:    new(slog.LevelVar),
+			fileWriter:      newFileWriter(config),
+			sampler:         newSampler(config.Sampling),
+			limiter:         newRateLimiter(config.RateLimit),
+			deduper:         newDeduper(config.Dedupe),
+			storms:          newStormGuard(config.ErrorStorm),
+			sizes:           newSizeLimiter(config.Limits),
+			transforms:      newTransforms(config),
+			clock:           config.Clock,
+			self:            newSelfLog(config.InternalLog),
+		},
 	}
+	ml.sinkErrors.stats = &ml.stats
+	ml.sinkErrors.self = ml.self
+	ml.fileWriter.self = ml.self
+	ml.fileWriter.report = func(err error) { ml.sinkErrors.report(SinkFile, err) }
 
 	// 设置初始级别
 	ml.consoleLevelVar.Set(config.LevelForConsole)
 	ml.fileLevelVar.Set(config.LevelForFile)
 
-	if config.LogToConsole {
-		ml.consoleLogger = slog.New(NewTxtColoredHandler(os.Stdout, &slog.HandlerOptions{
-			Level: ml.consoleLevelVar,
-		}))
+	// 两个输出总是创建，由 LogToConsole/LogToFile 控制是否输出，
+	// 这样 With 派生出的子 logger 在之后启用输出时也能正常工作
+	var fallback *lockedWriter
+	if config.Fallback != nil {
+		fallback = &lockedWriter{w: config.Fallback}
+		ml.fileWriter.fallback = fallback
 	}
-
-	if config.LogToFile {
-		dir, _ := filepath.Split(config.LogFilePath)
-		if len(dir) > 0 {
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return nil, err
-			}
+	// 日志文件和 Outputs 格式相同，共用一个 jsonSink
+	jsonWriters := []io.Writer{withFallback(ml.fileWriter, fallback, config.FallbackAfter)}
+	jsonSinks := []string{SinkFile}
+	for _, o := range config.Outputs {
+		jsonSinks = append(jsonSinks, o.Name)
+		w := o.Writer
+		if o.DeadLetter != "" {
+			d := &deadLetterWriter{name: o.Name, w: w, path: o.DeadLetter}
+			ml.deadLetters = append(ml.deadLetters, d)
+			w = d
 		}
-		file, err := os.OpenFile(config.LogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		jsonWriters = append(jsonWriters, withFallback(w, fallback, config.FallbackAfter))
+	}
+	var consoleWriter io.Writer = os.Stdout
+	if config.ConsoleWriter != nil {
+		consoleWriter = config.ConsoleWriter
+	}
+	consoleWriter = withFallback(consoleWriter, fallback, config.FallbackAfter)
+	handlers := []slog.Handler{
+		&sinkHandler{on: &ml.consoleOn, inner: ml.async([]string{SinkConsole}, []io.Writer{consoleWriter}, func(ws []io.Writer) slog.Handler {
+			console := NewTxtColoredHandler(ws[0], &slog.HandlerOptions{
+				Level: ml.consoleLevelVar,
+			})
+			console.pii = config.PIIConsole
+			console.noColor = config.ConsoleNoColor
+			console.flatten = config.ConsoleFlattenGroups
+			console.lineColor = config.ConsoleLineColor
+			console.theme = config.ConsoleTheme
+			console.levelStyle = config.ConsoleLevelStyle
+			console.levelLabels = config.ConsoleLevelLabels
+			console.sortAttrs = config.ConsoleSortAttrs
+			console.pinned = config.ConsolePinnedKeys
+			return &reportingHandler{sink: SinkConsole, report: &ml.sinkErrors, inner: console}
+		})},
+		ml.async(jsonSinks, jsonWriters, func(ws []io.Writer) slog.Handler {
+			dests := []*jsonDest{{name: SinkFile, on: &ml.fileOn, level: ml.fileLevelVar, w: ws[0], pii: config.PIIFile, format: config.FileFormat, overridable: true}}
+			for i, o := range config.Outputs {
+				d := &jsonDest{name: o.Name, level: o.Level, w: ws[i+1], pii: o.PII, format: o.Format}
+				if d.level == nil {
+					d.level = slog.LevelInfo
+				}
+				dests = append(dests, d)
+			}
+			return newJSONSink(dests, &ml.sinkErrors)
+		}),
+	}
+	if config.Audit != nil {
+		sink, fw, err := ml.newAuditSink(config.Audit)
 		if err != nil {
+			_ = ml.stopWorkers(context.Background())
 			return nil, err
 		}
-		ml.fileWriter = file // 保存文件写入器
-		ml.fileLogger = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{
-			Level: ml.fileLevelVar,
-		}))
+		ml.auditWriter = fw
+		handlers = append(handlers, sink)
+	}
+	if config.RingBuffer > 0 {
+		ml.ring = newRingSink(config.RingBuffer)
+		handlers = append(handlers, ml.ring)
+	}
+	handlers = append(handlers, config.Handlers...)
+	ml.handler = NewMultiHandler(handlers...)
+	if config.GoroutineID {
+		ml.self.printf("goroutine id", "GoroutineID is enabled: each record calls runtime.Stack, use it only for debugging")
+	}
+	if attrs := staticAttrs(config); len(attrs) > 0 {
+		ml.handler = ml.handler.withAttrs(ml.sizes.attrs(ml.transformAttrs(attrs)))
 	}
 
+	if config.LogToFile {
+		file, err := openLogFile(config.LogFilePath)
+		if err != nil {
+			_ = ml.Shutdown(context.Background())
+			return nil, err
+		}
+		ml.fileWriter.swap(file)
+	}
+	ml.consoleOn.Store(config.LogToConsole)
+	ml.fileOn.Store(config.LogToFile)
+	ml.fileWriter.start()
+
+	if config.BuildInfo == BuildInfoStartup {
+		r := slog.NewRecord(ml.now(), slog.LevelInfo, BuildInfoMessage, 0)
+		r.AddAttrs(buildAttrs()...)
+		_ = ml.Replay(context.Background(), r)
+	}
 	return ml, nil
 }
 
+// openLogFile 以追加方式打开日志文件，目录不存在时自动创建
+func openLogFile(path string) (*os.File, error) {
+	dir, _ := filepath.Split(path)
+	if len(dir) > 0 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+}
+
 // 设置控制台日志级别
 func (ml *Logger) SetConsoleLevel(level slog.Level) {
 	if ml.consoleLevelVar != nil {
@@ -185,47 +607,35 @@ func (ml *Logger) GetFileLevel() slog.Level {
 
 // 启用/禁用控制台日志
 func (ml *Logger) EnableConsole(enable bool) {
-	if enable && !ml.config.LogToConsole && ml.consoleLogger == nil {
-		ml.consoleLevelVar = new(slog.LevelVar)
-		ml.consoleLevelVar.Set(ml.config.LevelForConsole)
-		ml.consoleLogger = slog.New(NewTxtColoredHandler(os.Stdout, &slog.HandlerOptions{
-			Level: ml.consoleLevelVar,
-		}))
-	}
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
 	ml.config.LogToConsole = enable
+	ml.consoleOn.Store(enable)
 }
 
 // 启用/禁用文件日志
 func (ml *Logger) EnableFile(enable bool) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
 	// 如果要禁用且当前已启用
 	if !enable && ml.config.LogToFile {
 		ml.config.LogToFile = false
-		// 如果有文件句柄，可以考虑关闭它
-		if closer, ok := ml.fileWriter.(io.Closer); ok {
-			return closer.Close()
-		}
-		return nil
+		// 异步模式下在后台 goroutine 中才检查开关，先写完队列中的记录
+		ml.flushWorkers()
+		ml.fileOn.Store(false)
+		return ml.fileWriter.Close()
 	}
 
 	// 如果要启用且当前未启用
-	if enable && !ml.config.LogToFile && ml.fileLogger == nil {
-		dir, _ := filepath.Split(ml.config.LogFilePath)
-		if len(dir) > 0 {
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return err
-			}
-		}
-		file, err := os.OpenFile(ml.config.LogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if enable && !ml.config.LogToFile {
+		file, err := openLogFile(ml.config.LogFilePath)
 		if err != nil {
 			return err
 		}
-		ml.fileWriter = file
-		ml.fileLevelVar = new(slog.LevelVar)
-		ml.fileLevelVar.Set(ml.config.LevelForFile)
-		ml.fileLogger = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{
-			Level: ml.fileLevelVar,
-		}))
+		ml.fileWriter.swap(file)
 		ml.config.LogToFile = true
+		ml.fileOn.Store(true)
 	}
 
 	return nil
@@ -233,6 +643,9 @@ func (ml *Logger) EnableFile(enable bool) error {
 
 // ChangeFilePath 更改文件路径
 func (ml *Logger) ChangeFilePath(newPath string) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
 	// 如果新路径与当前路径相同，无需操作
 	if ml.config.LogFilePath == newPath {
 		return nil
@@ -244,74 +657,288 @@ func (ml *Logger) ChangeFilePath(newPath string) error {
 		return nil
 	}
 
-	// 关闭现有的文件
-	if closer, ok := ml.fileWriter.(io.Closer); ok {
-		if err := closer.Close(); err != nil {
-			return fmt.Errorf("failed to close existing log file: %w", err)
-		}
-	}
-
-	// 创建新文件
-	dir := filepath.Dir(newPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory for new log file: %w", err)
-	}
-
-	file, err := os.OpenFile(newPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	// 先打开新文件，成功后再替换，避免切换期间丢失日志
+	file, err := openLogFile(newPath)
 	if err != nil {
 		return fmt.Errorf("failed to open new log file: %w", err)
 	}
 
-	// 更新配置和日志器
+	// 更新配置并关闭旧文件，异步队列中的记录仍写入旧文件
+	ml.flushWorkers()
 	ml.config.LogFilePath = newPath
-	ml.fileWriter = file
-	ml.fileLogger = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{
-		Level: ml.fileLevelVar,
-	}))
+	if old := ml.fileWriter.swap(file); old != nil {
+		if err := old.Close(); err != nil {
+			return fmt.Errorf("failed to close existing log file: %w", err)
+		}
+	}
 
 	return nil
 }
 
-// 关闭日志器，清理资源
+// 关闭日志器，清理资源；异步模式下先写完队列中的记录，
+// 最多等待 SetShutdownTimeout 设置的时间
 func (ml *Logger) Close() error {
-	if closer, ok := ml.fileWriter.(io.Closer); ok {
-		return closer.Close()
+	ctx := context.Background()
+	if d := time.Duration(ml.shutdownTimeout.Load()); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
 	}
-	return nil
+	return ml.Shutdown(ctx)
+}
+
+func (ml *Logger) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	ml.log(ctx, level, msg, args...)
 }
 
 func (ml *Logger) Info(msg string, args ...any) {
-	if ml.config.LogToConsole && ml.consoleLogger != nil {
-		ml.consoleLogger.Info(msg, args...)
+	ml.log(context.Background(), slog.LevelInfo, msg, args...)
+}
+
+func (ml *Logger) Warn(msg string, args ...any) {
+	ml.log(context.Background(), slog.LevelWarn, msg, args...)
+}
+
+func (ml *Logger) Error(msg string, args ...any) {
+	ml.log(context.Background(), slog.LevelError, msg, args...)
+}
+
+func (ml *Logger) Debug(msg string, args ...any) {
+	ml.log(context.Background(), slog.LevelDebug, msg, args...)
+}
+
+func (ml *Logger) Infof(format string, args ...any) {
+	ml.logf(slog.LevelInfo, format, args...)
+}
+
+func (ml *Logger) Warnf(format string, args ...any) {
+	ml.logf(slog.LevelWarn, format, args...)
+}
+
+func (ml *Logger) Errorf(format string, args ...any) {
+	ml.logf(slog.LevelError, format, args...)
+}
+
+func (ml *Logger) Debugf(format string, args ...any) {
+	ml.logf(slog.LevelDebug, format, args...)
+}
+
+func (ml *Logger) InfoContext(ctx context.Context, msg string, args ...any) {
+	ml.log(ctx, slog.LevelInfo, msg, args...)
+}
+
+func (ml *Logger) WarnContext(ctx context.Context, msg string, args ...any) {
+	ml.log(ctx, slog.LevelWarn, msg, args...)
+}
+
+func (ml *Logger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	ml.log(ctx, slog.LevelError, msg, args...)
+}
+
+func (ml *Logger) DebugContext(ctx context.Context, msg string, args ...any) {
+	ml.log(ctx, slog.LevelDebug, msg, args...)
+}
+
+// log 构造日志记录并分发到控制台和文件
+func (ml *Logger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	if ml.config.LogToFile && ml.fileLogger != nil {
-		ml.fileLogger.Info(msg, args...)
+	if !ml.enabled(ctx, level) {
+		return
 	}
+
+	// 调用栈：0 runtime.Callers, 1 log, 2 Info 等公开方法, 3 调用方
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	r := slog.NewRecord(ml.now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = ml.handle(ctx, r)
 }
 
-func (ml *Logger) Warn(msg string, args ...any) {
-	if ml.config.LogToConsole && ml.consoleLogger != nil {
-		ml.consoleLogger.Warn(msg, args...)
+// logf 与 log 相同，但只在会被记录时才格式化消息
+func (ml *Logger) logf(level slog.Level, format string, args ...any) {
+	ctx := context.Background()
+	if !ml.enabled(ctx, level) {
+		return
 	}
-	if ml.config.LogToFile && ml.fileLogger != nil {
-		ml.fileLogger.Warn(msg, args...)
+
+	// 调用栈：0 runtime.Callers, 1 logf, 2 Infof 等公开方法, 3 调用方
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	r := slog.NewRecord(ml.now(), level, fmt.Sprintf(format, args...), pcs[0])
+	_ = ml.handle(ctx, r)
+}
+
+// now 返回当前时间，配置了 LogConfig.Clock 时使用它
+func (ml *Logger) now() time.Time {
+	if ml.clock != nil {
+		return ml.clock()
 	}
+	return time.Now()
 }
 
-func (ml *Logger) Error(msg string, args ...any) {
-	if ml.config.LogToConsole && ml.consoleLogger != nil {
-		ml.consoleLogger.Error(msg, args...)
+// handle 附加名称和 context 属性后把记录交给各输出
+func (ml *Logger) handle(ctx context.Context, r slog.Record) error {
+	// 命中覆盖规则时只按规则级别过滤，忽略各输出自身的级别
+	override, matched := ml.levelRules.lookup(ml.name, r.PC)
+	if matched && r.Level < override {
+		return nil
+	}
+	// 先确定要写入的输出，再附加属性；记录只构造一次，分发给各个输出
+	var buf [4]slog.Handler
+	targets := ml.handler.targets(ctx, r.Level, matched, buf[:0])
+	if len(targets) == 0 {
+		return nil
+	}
+	if !ml.sampler.allow(r) {
+		ml.stats.sampled.Add(1)
+		return nil
+	}
+	keep, suppressed := ml.limiter.allow(ctx, ml, r, matched)
+	if !keep {
+		ml.stats.rateLimited.Add(1)
+		return nil
 	}
-	if ml.config.LogToFile && ml.fileLogger != nil {
-		ml.fileLogger.Error(msg, args...)
+	keep, stormSuppressed, occurrences := ml.storms.allow(r)
+	if !keep {
+		ml.stats.stormed.Add(1)
+		return nil
 	}
+	ml.stats.records.add(r.Level)
+	return ml.write(ctx, r, targets, matched, suppressed+stormSuppressed, occurrences)
 }
 
-func (ml *Logger) Debug(msg string, args ...any) {
-	if ml.config.LogToConsole && ml.consoleLogger != nil {
-		ml.consoleLogger.Debug(msg, args...)
+// write 附加 xslog 的属性后把记录交给去重或各输出；suppressed 是限流和错误风暴丢弃的条数之和，
+// 与 occurrences 一样为 0 时不附加
+func (ml *Logger) write(ctx context.Context, r slog.Record, targets []slog.Handler, matched bool, suppressed, occurrences int) error {
+	// 先截断调用方给出的消息和属性，xslog 附加的 logger、seq 等属性不占 MaxAttrs，也不会被截掉
+	r = ml.sizes.record(ml.transformRecord(r))
+	attrs := ml.contextAttrs(ctx)
+	withStack := ml.config.StackTraceLevel != nil && r.Level >= ml.config.StackTraceLevel.Level()
+	if ml.name != "" || ml.config.Sequence || ml.config.MonotonicTime || ml.config.GoroutineID || len(attrs) > 0 || withStack || suppressed > 0 || occurrences > 0 {
+		r = r.Clone()
+		if ml.name != "" {
+			r.AddAttrs(slog.String(LoggerNameKey, ml.name))
+		}
+		if ml.config.Sequence {
+			r.AddAttrs(slog.Uint64(SeqKey, ml.seq.Add(1)))
+		}
+		if ml.config.MonotonicTime {
+			r.AddAttrs(slog.Duration(MonotonicKey, monotonicSince(r.Time)))
+		}
+		if ml.config.GoroutineID {
+			r.AddAttrs(slog.Uint64(GoroutineKey, goroutineID()))
+		}
+		r.AddAttrs(ml.sizes.attrs(ml.transformAttrs(attrs))...)
+		if suppressed > 0 {
+			r.AddAttrs(slog.Int(SuppressedKey, suppressed))
+		}
+		if occurrences > 0 {
+			r.AddAttrs(slog.Int(OccurrencesKey, occurrences))
+		}
+		if withStack {
+			r.AddAttrs(slog.Any(StackKey, callerStack()))
+		}
 	}
-	if ml.config.LogToFile && ml.fileLogger != nil {
-		ml.fileLogger.Debug(msg, args...)
+	if ml.deduper != nil {
+		return ml.deduper.handle(ctx, ml.handler, r, targets, matched)
 	}
+	return dispatch(ctx, r, targets, matched)
+}
+
+// Enabled 报告 level 级别的日志是否会被任一输出记录，
+// 可以在构造开销较大的参数前先判断，与 slog.Logger.Enabled 一致
+func (ml *Logger) Enabled(ctx context.Context, level slog.Level) bool {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ml.levelRules.empty() {
+		return ml.handler.Enabled(ctx, level)
+	}
+	// 有覆盖规则时按调用方包路径精确判断
+	var pc uintptr
+	if ml.name == "" {
+		var pcs [1]uintptr
+		runtime.Callers(2, pcs[:])
+		pc = pcs[0]
+	}
+	if override, matched := ml.levelRules.lookup(ml.name, pc); matched {
+		return level >= override && (ml.consoleOn.Load() || ml.fileOn.Load() || len(ml.config.Outputs) > 0 || len(ml.config.Handlers) > 0)
+	}
+	return ml.handler.Enabled(ctx, level)
+}
+
+// enabled 判断 level 级别的记录是否会被任一输出记录。
+// 未命名的 logger 在 Enabled 阶段拿不到调用方包路径，按最宽松的覆盖规则判断，
+// 精确过滤留给 handle
+func (ml *Logger) enabled(ctx context.Context, level slog.Level) bool {
+	if ml.handler.Enabled(ctx, level) {
+		return true
+	}
+	if !ml.consoleOn.Load() && !ml.fileOn.Load() {
+		return false
+	}
+	if ml.name != "" {
+		override, matched := ml.levelRules.lookup(ml.name, 0)
+		return matched && level >= override
+	}
+	min, ok := ml.levelRules.min()
+	return ok && level >= min
+}
+
+// With 返回附加了 args 属性的子 logger，控制台和文件输出都会带上这些属性。
+// 子 logger 与父 logger 共享级别、输出开关和日志文件
+func (ml *Logger) With(args ...any) *Logger {
+	if len(args) == 0 {
+		return ml
+	}
+	child := *ml
+	child.handler = ml.handler.withAttrs(ml.sizes.attrs(ml.transformAttrs(argsToAttrs(args))))
+	return &child
+}
+
+// WithGroup 返回一个子 logger，之后附加的属性都放在 name 分组下，
+// 与 slog.Logger.WithGroup 一致，对控制台和文件同时生效
+func (ml *Logger) WithGroup(name string) *Logger {
+	if name == "" {
+		return ml
+	}
+	child := *ml
+	child.handler = ml.handler.withGroup(name)
+	return &child
+}
+
+// argsToAttrs 按 slog 的规则把 "key", value 形式的参数转换为属性
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+// Named 返回一个带名称的子 logger，多次调用时名称以 "." 连接，
+// 例如 logger.Named("db").Named("pool") 的名称为 "db.pool"
+func (ml *Logger) Named(name string) *Logger {
+	if name == "" {
+		return ml
+	}
+	child := *ml
+	if ml.name != "" {
+		child.name = ml.name + "." + name
+	} else {
+		child.name = name
+	}
+	return &child
+}
+
+// Name 返回 logger 的名称，未命名时为空
+func (ml *Logger) Name() string {
+	return ml.name
 }
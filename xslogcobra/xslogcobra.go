@@ -0,0 +1,111 @@
+// Package xslogcobra 把 xslog 的配置绑定到 Cobra 持久参数和 Viper 配置键，
+// 在 PersistentPreRunE 中构建 Logger。
+package xslogcobra
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xbfding/xslog"
+)
+
+// 绑定的 Viper 配置键
+const (
+	KeyLevel     = "log.level"      // 控制台和文件的级别
+	KeyVerbose   = "log.verbose"    // -v 的次数，未设置 log.level 时生效
+	KeyConsole   = "log.console"    // 是否输出到控制台，默认 true
+	KeyFile      = "log.file"       // 日志文件路径，不为空时输出到文件
+	KeyFileLevel = "log.file_level" // 单独指定文件级别，默认与 log.level 相同
+)
+
+// Binding 保存绑定后构建出的 Logger
+type Binding struct {
+	v      *viper.Viper
+	logger *xslog.Logger
+}
+
+// Bind 在 cmd 上注册持久参数 --log-level、-v/--verbose、--log-console、--log-file、--log-file-level，
+// 绑定到 v 的对应配置键（v 为 nil 时使用 viper 的全局实例），并包装 cmd.PersistentPreRunE：
+// 执行子命令前构建 Logger，设为 xslog 默认 logger，并放入命令的 context。
+// 原有的 PersistentPreRunE/PersistentPreRun 仍会在之后执行
+func Bind(cmd *cobra.Command, v *viper.Viper) *Binding {
+	if v == nil {
+		v = viper.GetViper()
+	}
+	b := &Binding{v: v}
+
+	flags := cmd.PersistentFlags()
+	flags.String("log-level", "", "log level: trace, debug, info, warn, error, or a level such as warn+2")
+	flags.CountP("verbose", "v", "verbose logging, repeat for more (-v debug, -vv trace)")
+	flags.Bool("log-console", true, "write logs to the console")
+	flags.String("log-file", "", "also write JSON logs to this file")
+	flags.String("log-file-level", "", "log level for the file, defaults to --log-level")
+	for key, name := range map[string]string{
+		KeyLevel:     "log-level",
+		KeyVerbose:   "verbose",
+		KeyConsole:   "log-console",
+		KeyFile:      "log-file",
+		KeyFileLevel: "log-file-level",
+	} {
+		_ = v.BindPFlag(key, flags.Lookup(name))
+	}
+
+	preRunE, preRun := cmd.PersistentPreRunE, cmd.PersistentPreRun
+	cmd.PersistentPreRun = nil
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		logger, err := b.build()
+		if err != nil {
+			return err
+		}
+		b.logger = logger
+		xslog.SetDefault(logger)
+		c.SetContext(xslog.NewContext(c.Context(), logger))
+
+		if preRunE != nil {
+			return preRunE(c, args)
+		}
+		if preRun != nil {
+			preRun(c, args)
+		}
+		return nil
+	}
+	return b
+}
+
+// Logger 返回 PersistentPreRunE 中构建的 Logger，命令执行前为 nil
+func (b *Binding) Logger() *xslog.Logger {
+	return b.logger
+}
+
+func (b *Binding) build() (*xslog.Logger, error) {
+	// 设置了 log.level（参数、配置文件或环境变量）时优先于 -v
+	level := slog.LevelInfo - slog.Level(4*b.v.GetInt(KeyVerbose))
+	if s := b.v.GetString(KeyLevel); s != "" {
+		parsed, err := xslog.ParseLevel(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", KeyLevel, err)
+		}
+		level = parsed
+	}
+	fileLevel := level
+	if s := b.v.GetString(KeyFileLevel); s != "" {
+		parsed, err := xslog.ParseLevel(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", KeyFileLevel, err)
+		}
+		fileLevel = parsed
+	}
+
+	path := b.v.GetString(KeyFile)
+	return xslog.NewLogger(xslog.LogConfig{
+		LogToConsole:    b.v.GetBool(KeyConsole),
+		LogToFile:       path != "",
+		LogFilePath:     path,
+		LevelForConsole: level,
+		LevelForFile:    fileLevel,
+		// 命令行显式指定了级别时，不再让 LOG_LEVEL 等环境变量覆盖
+		IgnoreEnv: b.v.GetString(KeyLevel) != "" || b.v.GetInt(KeyVerbose) > 0,
+	})
+}
@@ -0,0 +1,129 @@
+// Package xslogecho 提供 Echo 的请求日志和 panic 恢复中间件，通过 xslog 记录。
+package xslogecho
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/xbfding/xslog"
+)
+
+// RequestIDKey 是请求 ID 在日志记录和 echo.Context 中的键名
+const RequestIDKey = "request_id"
+
+// Option 配置 Middleware
+type Option func(*options)
+
+type options struct {
+	skipPaths map[string]bool
+	level     func(status int) slog.Level
+}
+
+// WithSkipPaths 不记录这些路径（精确匹配）的请求，如健康检查 "/healthz"
+func WithSkipPaths(paths ...string) Option {
+	return func(o *options) {
+		for _, p := range paths {
+			o.skipPaths[p] = true
+		}
+	}
+}
+
+// WithLevel 按状态码决定请求日志的级别，默认 5xx 为 Error，4xx 为 Warn，其他为 Info
+func WithLevel(fn func(status int) slog.Level) Option {
+	return func(o *options) {
+		o.level = fn
+	}
+}
+
+func defaultLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// Middleware 返回记录每个请求并恢复 panic 的中间件，应该作为第一个中间件注册：
+//
+//	e := echo.New()
+//	e.Use(xslogecho.Middleware(logger))
+//
+// 请求的 context 中带有附加了 request_id 的 logger（见 xslog.FromContext）；
+// handler 返回的错误交给 Echo 的 HTTPErrorHandler 处理后再记录，panic 时记录堆栈并返回 500
+func Middleware(logger *xslog.Logger, opts ...Option) echo.MiddlewareFunc {
+	o := &options{skipPaths: map[string]bool{}, level: defaultLevel}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			start := time.Now()
+			req, res := c.Request(), c.Response()
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if id == "" {
+				id = newRequestID()
+			}
+			res.Header().Set(echo.HeaderXRequestID, id)
+			c.Set(RequestIDKey, id)
+			reqLogger := logger.With(RequestIDKey, id)
+			req = req.WithContext(xslog.NewContext(req.Context(), reqLogger))
+			c.SetRequest(req)
+
+			defer func() {
+				if v := recover(); v != nil {
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+					reqLogger.Log(req.Context(), slog.LevelError, "panic recovered",
+						slog.Any(xslog.PanicKey, v),
+						slog.String(xslog.HTTPMethodKey, req.Method),
+						slog.String(xslog.HTTPPathKey, req.URL.RequestURI()),
+						xslog.Stack(0),
+					)
+					c.Error(echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprint(v)))
+				}
+				if o.skipPaths[req.URL.Path] {
+					return
+				}
+				attrs := []any{
+					slog.String(xslog.HTTPMethodKey, req.Method),
+					slog.String(xslog.HTTPPathKey, req.URL.RequestURI()),
+					slog.String(xslog.HTTPProtoKey, req.Proto),
+					slog.Int(xslog.HTTPStatusKey, res.Status),
+					slog.Int64(xslog.HTTPBytesKey, res.Size),
+					slog.Duration(xslog.HTTPLatencyKey, time.Since(start)),
+					slog.String(xslog.HTTPClientIPKey, c.RealIP()),
+				}
+				if route := c.Path(); route != "" {
+					attrs = append(attrs, slog.String("route", route))
+				}
+				if ua := req.UserAgent(); ua != "" {
+					attrs = append(attrs, slog.String(xslog.HTTPUserAgentKey, ua))
+				}
+				if err != nil {
+					attrs = append(attrs, xslog.Err(err))
+				}
+				reqLogger.Log(req.Context(), o.level(res.Status), "request", attrs...)
+				// 错误已经写入响应，不再交给上层重复处理
+				err = nil
+			}()
+			if err = next(c); err != nil {
+				c.Error(err)
+			}
+			return err
+		}
+	}
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
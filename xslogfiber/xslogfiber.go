@@ -0,0 +1,128 @@
+// Package xslogfiber 提供 Fiber 的请求日志和 panic 恢复中间件，通过 xslog 记录。
+package xslogfiber
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xbfding/xslog"
+)
+
+// RequestIDKey 是请求 ID 在日志记录和 fiber.Ctx.Locals 中的键名
+const RequestIDKey = "request_id"
+
+// Option 配置 Middleware
+type Option func(*options)
+
+type options struct {
+	skipPaths map[string]bool
+	level     func(status int) slog.Level
+}
+
+// WithSkipPaths 不记录这些路径（精确匹配）的请求，如健康检查 "/healthz"
+func WithSkipPaths(paths ...string) Option {
+	return func(o *options) {
+		for _, p := range paths {
+			o.skipPaths[p] = true
+		}
+	}
+}
+
+// WithLevel 按状态码决定请求日志的级别，默认 5xx 为 Error，4xx 为 Warn，其他为 Info
+func WithLevel(fn func(status int) slog.Level) Option {
+	return func(o *options) {
+		o.level = fn
+	}
+}
+
+func defaultLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// Middleware 返回记录每个请求并恢复 panic 的中间件，应该作为第一个中间件注册：
+//
+//	app := fiber.New()
+//	app.Use(xslogfiber.Middleware(logger))
+//
+// c.UserContext() 中带有附加了 request_id 的 logger（见 xslog.FromContext）；
+// handler 返回的错误交给 App 的 ErrorHandler 处理后再记录，panic 时记录堆栈并返回 500
+func Middleware(logger *xslog.Logger, opts ...Option) fiber.Handler {
+	o := &options{skipPaths: map[string]bool{}, level: defaultLevel}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(c *fiber.Ctx) (err error) {
+		start := time.Now()
+		// fasthttp 会复用 Ctx 返回的字符串，进入日志记录（可能异步输出）的值都先复制
+		id := strings.Clone(c.Get(fiber.HeaderXRequestID))
+		if id == "" {
+			id = newRequestID()
+		}
+		c.Set(fiber.HeaderXRequestID, id)
+		c.Locals(RequestIDKey, id)
+		reqLogger := logger.With(RequestIDKey, id)
+		ctx := xslog.NewContext(c.UserContext(), reqLogger)
+		c.SetUserContext(ctx)
+
+		defer func() {
+			if v := recover(); v != nil {
+				reqLogger.Log(ctx, slog.LevelError, "panic recovered",
+					slog.Any(xslog.PanicKey, v),
+					slog.String(xslog.HTTPMethodKey, strings.Clone(c.Method())),
+					slog.String(xslog.HTTPPathKey, strings.Clone(c.OriginalURL())),
+					xslog.Stack(0),
+				)
+				err = fiber.NewError(fiber.StatusInternalServerError, fmt.Sprint(v))
+			}
+			if err != nil {
+				if herr := c.App().ErrorHandler(c, err); herr != nil {
+					_ = c.SendStatus(fiber.StatusInternalServerError)
+				}
+			}
+			if o.skipPaths[c.Path()] {
+				err = nil
+				return
+			}
+			status := c.Response().StatusCode()
+			attrs := []any{
+				slog.String(xslog.HTTPMethodKey, strings.Clone(c.Method())),
+				slog.String(xslog.HTTPPathKey, strings.Clone(c.OriginalURL())),
+				slog.String(xslog.HTTPProtoKey, string(c.Request().Header.Protocol())),
+				slog.Int(xslog.HTTPStatusKey, status),
+				slog.Int(xslog.HTTPBytesKey, len(c.Response().Body())),
+				slog.Duration(xslog.HTTPLatencyKey, time.Since(start)),
+				slog.String(xslog.HTTPClientIPKey, strings.Clone(c.IP())),
+			}
+			if route := c.Route().Path; route != "" {
+				attrs = append(attrs, slog.String("route", strings.Clone(route)))
+			}
+			if ua := c.Get(fiber.HeaderUserAgent); ua != "" {
+				attrs = append(attrs, slog.String(xslog.HTTPUserAgentKey, strings.Clone(ua)))
+			}
+			if err != nil {
+				attrs = append(attrs, xslog.Err(err))
+			}
+			reqLogger.Log(ctx, o.level(status), "request", attrs...)
+			// 错误已经写入响应，不再交给上层重复处理
+			err = nil
+		}()
+		return c.Next()
+	}
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
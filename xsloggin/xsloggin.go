@@ -0,0 +1,124 @@
+// Package xsloggin 提供 Gin 的请求日志和 panic 恢复中间件，通过 xslog 记录，
+// 代替绕过 slog 的 gin.Logger 和 gin.Recovery。
+package xsloggin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbfding/xslog"
+)
+
+// RequestIDKey 是请求 ID 在日志记录和 gin.Context 中的键名
+const RequestIDKey = "request_id"
+
+// 读取和返回请求 ID 的请求头
+const requestIDHeader = "X-Request-ID"
+
+// Option 配置 Middleware
+type Option func(*options)
+
+type options struct {
+	skipPaths map[string]bool
+	level     func(status int) slog.Level
+}
+
+// WithSkipPaths 不记录这些路径（精确匹配）的请求，如健康检查 "/healthz"
+func WithSkipPaths(paths ...string) Option {
+	return func(o *options) {
+		for _, p := range paths {
+			o.skipPaths[p] = true
+		}
+	}
+}
+
+// WithLevel 按状态码决定请求日志的级别，默认 5xx 为 Error，4xx 为 Warn，其他为 Info
+func WithLevel(fn func(status int) slog.Level) Option {
+	return func(o *options) {
+		o.level = fn
+	}
+}
+
+func defaultLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// Middleware 返回记录每个请求并恢复 panic 的中间件，应该作为第一个中间件注册：
+//
+//	r := gin.New()
+//	r.Use(xsloggin.Middleware(logger))
+//
+// 请求的 context 中带有附加了 request_id 的 logger（见 xslog.FromContext）；
+// panic 时记录 panic 值和堆栈并返回 500
+func Middleware(logger *xslog.Logger, opts ...Option) gin.HandlerFunc {
+	o := &options{skipPaths: map[string]bool{}, level: defaultLevel}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.GetHeader(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		c.Header(requestIDHeader, id)
+		c.Set(RequestIDKey, id)
+		reqLogger := logger.With(RequestIDKey, id)
+		c.Request = c.Request.WithContext(xslog.NewContext(c.Request.Context(), reqLogger))
+
+		defer func() {
+			if v := recover(); v != nil {
+				// http.ErrAbortHandler 用于主动中断响应，按 net/http 的约定继续向上抛出
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				reqLogger.Log(c.Request.Context(), slog.LevelError, "panic recovered",
+					slog.Any(xslog.PanicKey, v),
+					slog.String(xslog.HTTPMethodKey, c.Request.Method),
+					slog.String(xslog.HTTPPathKey, c.Request.URL.RequestURI()),
+					xslog.Stack(0),
+				)
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+			if o.skipPaths[c.Request.URL.Path] {
+				return
+			}
+			attrs := []any{
+				slog.String(xslog.HTTPMethodKey, c.Request.Method),
+				slog.String(xslog.HTTPPathKey, c.Request.URL.RequestURI()),
+				slog.String(xslog.HTTPProtoKey, c.Request.Proto),
+				slog.Int(xslog.HTTPStatusKey, c.Writer.Status()),
+				slog.Int(xslog.HTTPBytesKey, max(c.Writer.Size(), 0)),
+				slog.Duration(xslog.HTTPLatencyKey, time.Since(start)),
+				slog.String(xslog.HTTPClientIPKey, c.ClientIP()),
+			}
+			if route := c.FullPath(); route != "" {
+				attrs = append(attrs, slog.String("route", route))
+			}
+			if ua := c.Request.UserAgent(); ua != "" {
+				attrs = append(attrs, slog.String(xslog.HTTPUserAgentKey, ua))
+			}
+			if len(c.Errors) > 0 {
+				attrs = append(attrs, slog.String("errors", c.Errors.String()))
+			}
+			reqLogger.Log(c.Request.Context(), o.level(c.Writer.Status()), "request", attrs...)
+		}()
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,154 @@
+// Package xsloggrpc 提供记录 gRPC 调用的服务端和客户端拦截器，每次调用记录一条日志：
+// 方法、状态码、耗时和对端地址；服务端拦截器把附加了方法名的 logger 放入 context。
+package xsloggrpc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/xbfding/xslog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// 日志记录中的属性名
+const (
+	MethodKey = "grpc.method" // 完整方法名，如 "/pkg.Service/Method"
+	CodeKey   = "grpc.code"   // 状态码名称，如 "OK"、"NotFound"
+	PeerKey   = "peer.address"
+)
+
+// Option 配置拦截器
+type Option func(*options)
+
+type options struct {
+	skip  map[string]bool
+	level func(codes.Code) slog.Level
+}
+
+// WithSkipMethods 不记录这些方法的调用，如健康检查 "/grpc.health.v1.Health/Check"
+func WithSkipMethods(methods ...string) Option {
+	return func(o *options) {
+		for _, m := range methods {
+			o.skip[m] = true
+		}
+	}
+}
+
+// WithLevel 按状态码决定日志级别，默认见 DefaultLevel
+func WithLevel(fn func(codes.Code) slog.Level) Option {
+	return func(o *options) {
+		o.level = fn
+	}
+}
+
+// DefaultLevel 是默认的级别映射：OK 为 Info，调用方引起的错误（如 NotFound、InvalidArgument）为 Warn，
+// 服务端错误（如 Internal、Unavailable）为 Error
+func DefaultLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{skip: map[string]bool{}, level: DefaultLevel}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// log 记录一次调用
+func (o *options) log(ctx context.Context, logger *xslog.Logger, msg string, start time.Time, err error) {
+	code := status.Code(err)
+	attrs := []any{
+		slog.String(CodeKey, code.String()),
+		slog.Duration(xslog.HTTPLatencyKey, time.Since(start)),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String(PeerKey, p.Addr.String()))
+	}
+	if err != nil {
+		attrs = append(attrs, xslog.Err(err))
+	}
+	logger.Log(ctx, o.level(code), msg, attrs...)
+}
+
+// UnaryServerInterceptor 返回记录一元调用的服务端拦截器，handler 可以用 xslog.FromContext 取得带方法名的 logger
+func UnaryServerInterceptor(logger *xslog.Logger, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if o.skip[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		l := logger.With(MethodKey, info.FullMethod)
+		ctx = xslog.NewContext(ctx, l)
+		resp, err := handler(ctx, req)
+		o.log(ctx, l, "grpc call", start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 返回记录流式调用的服务端拦截器，在流结束时记录
+func StreamServerInterceptor(logger *xslog.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if o.skip[info.FullMethod] {
+			return handler(srv, ss)
+		}
+		start := time.Now()
+		l := logger.With(MethodKey, info.FullMethod)
+		ctx := xslog.NewContext(ss.Context(), l)
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		o.log(ctx, l, "grpc stream", start, err)
+		return err
+	}
+}
+
+// serverStream 替换 ServerStream 的 context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor 返回记录一元调用的客户端拦截器
+func UnaryClientInterceptor(logger *xslog.Logger, opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		if o.skip[method] {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		o.log(ctx, logger.With(MethodKey, method, PeerKey, cc.Target()), "grpc client call", start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor 返回客户端流式调用的拦截器，只记录建立流的结果
+func StreamClientInterceptor(logger *xslog.Logger, opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if o.skip[method] {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		o.log(ctx, logger.With(MethodKey, method, PeerKey, cc.Target()), "grpc client stream", start, err)
+		return cs, err
+	}
+}
@@ -0,0 +1,298 @@
+// Package xsloghttp 提供 net/http 的请求日志中间件，每个请求记录一条日志：
+// 方法、路径、状态码、响应大小、耗时、客户端 IP 和请求 ID。
+package xsloghttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/xbfding/xslog"
+)
+
+// RequestIDKey 是请求 ID 在日志记录中的属性名
+const RequestIDKey = "request_id"
+
+// 默认读取和返回请求 ID 的请求头
+const defaultRequestIDHeader = "X-Request-ID"
+
+// 记录请求体和响应体时默认保留的最大字节数
+const defaultMaxBodyBytes = 4 << 10
+
+// Option 配置 Middleware
+type Option func(*options)
+
+type options struct {
+	skipPaths       map[string]bool
+	skip            func(*http.Request) bool
+	requestIDHeader string
+	clientIPHeader  string
+	logRequestBody  bool
+	logResponseBody bool
+	maxBodyBytes    int
+	level           func(status int) slog.Level
+}
+
+// WithSkipPaths 不记录这些路径（精确匹配）的请求，如健康检查 "/healthz"
+func WithSkipPaths(paths ...string) Option {
+	return func(o *options) {
+		for _, p := range paths {
+			o.skipPaths[p] = true
+		}
+	}
+}
+
+// WithSkip 不记录 fn 返回 true 的请求
+func WithSkip(fn func(*http.Request) bool) Option {
+	return func(o *options) {
+		o.skip = fn
+	}
+}
+
+// WithRequestIDHeader 设置读取和返回请求 ID 的请求头，默认为 "X-Request-ID"；
+// 请求中没有时生成一个新的 ID
+func WithRequestIDHeader(name string) Option {
+	return func(o *options) {
+		o.requestIDHeader = name
+	}
+}
+
+// WithClientIPHeader 从 name（如 "X-Forwarded-For"、"X-Real-IP"）读取客户端 IP，取第一个地址；
+// 只应在前面有可信代理时使用，否则客户端可以伪造。默认使用 RemoteAddr
+func WithClientIPHeader(name string) Option {
+	return func(o *options) {
+		o.clientIPHeader = name
+	}
+}
+
+// WithRequestBody 在 Debug 级别启用时记录请求体的前 WithMaxBodyBytes 字节
+func WithRequestBody() Option {
+	return func(o *options) {
+		o.logRequestBody = true
+	}
+}
+
+// WithResponseBody 在 Debug 级别启用时记录响应体的前 WithMaxBodyBytes 字节
+func WithResponseBody() Option {
+	return func(o *options) {
+		o.logResponseBody = true
+	}
+}
+
+// WithMaxBodyBytes 设置记录请求体和响应体时保留的最大字节数，默认 4KB
+func WithMaxBodyBytes(n int) Option {
+	return func(o *options) {
+		o.maxBodyBytes = n
+	}
+}
+
+// WithLevel 按状态码决定请求日志的级别，默认 5xx 为 Error，4xx 为 Warn，其他为 Info
+func WithLevel(fn func(status int) slog.Level) Option {
+	return func(o *options) {
+		o.level = fn
+	}
+}
+
+func defaultLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+type requestIDKey struct{}
+
+// RequestID 返回 Middleware 为当前请求分配的请求 ID，不在中间件内时返回空字符串
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDExtractor 可以传给 Logger.AddContextExtractor，
+// 让 XxxContext 方法记录的日志都带上 request_id
+func RequestIDExtractor(ctx context.Context) []slog.Attr {
+	if id := RequestID(ctx); id != "" {
+		return []slog.Attr{slog.String(RequestIDKey, id)}
+	}
+	return nil
+}
+
+// Middleware 返回记录每个请求的中间件。请求的 context 中带有请求 ID（见 RequestID）
+// 和附加了 request_id 的 logger（见 xslog.FromContext）
+//
+//	mux := http.NewServeMux()
+//	http.ListenAndServe(":8080", xsloghttp.Middleware(logger, xsloghttp.WithSkipPaths("/healthz"))(mux))
+func Middleware(logger *xslog.Logger, opts ...Option) func(http.Handler) http.Handler {
+	o := &options{
+		skipPaths:       map[string]bool{},
+		requestIDHeader: defaultRequestIDHeader,
+		maxBodyBytes:    defaultMaxBodyBytes,
+		level:           defaultLevel,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if o.skipPaths[r.URL.Path] || (o.skip != nil && o.skip(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+
+			id := r.Header.Get(o.requestIDHeader)
+			if id == "" {
+				id = newRequestID()
+			}
+			w.Header().Set(o.requestIDHeader, id)
+			reqLogger := logger.With(RequestIDKey, id)
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			ctx = xslog.NewContext(ctx, reqLogger)
+			r = r.WithContext(ctx)
+
+			debug := logger.Enabled(ctx, slog.LevelDebug)
+			var reqBody *limitedBuffer
+			if debug && o.logRequestBody && r.Body != nil && r.Body != http.NoBody {
+				reqBody = &limitedBuffer{max: o.maxBodyBytes}
+				r.Body = &teeReadCloser{Reader: io.TeeReader(r.Body, reqBody), Closer: r.Body}
+			}
+			rw := &responseWriter{ResponseWriter: w}
+			if debug && o.logResponseBody {
+				rw.body = &limitedBuffer{max: o.maxBodyBytes}
+			}
+
+			next.ServeHTTP(rw, r)
+
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			attrs := []any{
+				slog.String(xslog.HTTPMethodKey, r.Method),
+				slog.String(xslog.HTTPPathKey, r.URL.RequestURI()),
+				slog.String(xslog.HTTPProtoKey, r.Proto),
+				slog.Int(xslog.HTTPStatusKey, status),
+				slog.Int64(xslog.HTTPBytesKey, rw.bytes),
+				slog.Duration(xslog.HTTPLatencyKey, time.Since(start)),
+				slog.String(xslog.HTTPClientIPKey, o.clientIP(r)),
+			}
+			if ua := r.UserAgent(); ua != "" {
+				attrs = append(attrs, slog.String(xslog.HTTPUserAgentKey, ua))
+			}
+			if ref := r.Referer(); ref != "" {
+				attrs = append(attrs, slog.String(xslog.HTTPRefererKey, ref))
+			}
+			reqLogger.Log(ctx, o.level(status), "request", attrs...)
+			if reqBody != nil || rw.body != nil {
+				attrs = attrs[:0]
+				if reqBody != nil {
+					attrs = append(attrs, reqBody.attr("request_body"))
+				}
+				if rw.body != nil {
+					attrs = append(attrs, rw.body.attr("response_body"))
+				}
+				reqLogger.Log(ctx, slog.LevelDebug, "request body", attrs...)
+			}
+		})
+	}
+}
+
+func (o *options) clientIP(r *http.Request) string {
+	if o.clientIPHeader != "" {
+		if v := r.Header.Get(o.clientIPHeader); v != "" {
+			first, _, _ := strings.Cut(v, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter 记录状态码、写出的字节数，需要时保留响应体的开头
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+	body   *limitedBuffer
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	if w.body != nil {
+		_, _ = w.body.Write(p[:n])
+	}
+	return n, err
+}
+
+// Flush 让流式响应（如 SSE）在包装后仍然可以刷新
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 访问底层的 ResponseWriter
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// limitedBuffer 最多保留 max 字节，之后的内容只计数
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	max   int
+	total int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	b.total += n
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			p = p[:room]
+		}
+		b.buf.Write(p)
+	}
+	return n, nil
+}
+
+func (b *limitedBuffer) attr(key string) slog.Attr {
+	if b.total > b.buf.Len() {
+		return slog.Group(key, slog.String("body", b.buf.String()), slog.Int("size", b.total), slog.Bool(xslog.TruncatedKey, true))
+	}
+	return slog.String(key, b.buf.String())
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
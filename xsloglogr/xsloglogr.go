@@ -0,0 +1,85 @@
+// Package xsloglogr 提供基于 xslog 的 logr.LogSink，
+// 让 controller-runtime、Kubernetes client 等使用 logr 的代码写到同一组输出。
+package xsloglogr
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/xbfding/xslog"
+)
+
+// New 返回写入 logger 的 logr.Logger
+//
+//	ctrl.SetLogger(xsloglogr.New(logger))
+func New(logger *xslog.Logger) logr.Logger {
+	return logr.New(NewLogSink(logger))
+}
+
+// LogSink 把 logr 的调用转交给 xslog.Logger。V 级别 v 对应 slog.Level(-v)：
+// V(0) 为 Info，V(4) 为 Debug，V(8) 为 Trace；WithName 的名称按 xslog.Logger.Named 的方式以 "." 连接
+type LogSink struct {
+	logger  *xslog.Logger
+	handler slog.Handler
+	depth   int // logr 和 WithCallDepth 附加的调用深度
+}
+
+// NewLogSink 返回写入 logger 的 LogSink
+func NewLogSink(logger *xslog.Logger) *LogSink {
+	return &LogSink{logger: logger, handler: logger.Handler()}
+}
+
+var (
+	_ logr.LogSink          = (*LogSink)(nil)
+	_ logr.CallDepthLogSink = (*LogSink)(nil)
+)
+
+func (s *LogSink) Init(info logr.RuntimeInfo) {
+	s.depth += info.CallDepth
+}
+
+func (s *LogSink) Enabled(level int) bool {
+	return s.handler.Enabled(context.Background(), slog.Level(-level))
+}
+
+func (s *LogSink) Info(level int, msg string, keysAndValues ...any) {
+	s.log(slog.Level(-level), msg, keysAndValues)
+}
+
+func (s *LogSink) Error(err error, msg string, keysAndValues ...any) {
+	s.log(slog.LevelError, msg, append([]any{xslog.Err(err)}, keysAndValues...))
+}
+
+func (s *LogSink) log(level slog.Level, msg string, kv []any) {
+	ctx := context.Background()
+	if !s.handler.Enabled(ctx, level) {
+		return
+	}
+	// 调用栈：0 runtime.Callers, 1 log, 2 Info/Error, 3 logr.Logger 的方法（由 depth 跳过）之后是调用方
+	var pcs [1]uintptr
+	runtime.Callers(3+s.depth, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(kv...)
+	_ = s.handler.Handle(ctx, r)
+}
+
+func (s *LogSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return s.with(s.logger.With(keysAndValues...))
+}
+
+func (s *LogSink) WithName(name string) logr.LogSink {
+	return s.with(s.logger.Named(name))
+}
+
+func (s *LogSink) WithCallDepth(depth int) logr.LogSink {
+	s2 := *s
+	s2.depth += depth
+	return &s2
+}
+
+func (s *LogSink) with(logger *xslog.Logger) *LogSink {
+	return &LogSink{logger: logger, handler: logger.Handler(), depth: s.depth}
+}
@@ -0,0 +1,91 @@
+// Package xsloglogrus 把 logrus 的日志转发到 xslog，便于逐步迁移仍在使用 logrus 的代码
+package xsloglogrus
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xbfding/xslog"
+)
+
+// Hook 是把每条 logrus 记录写到 xslog.Logger 的 logrus.Hook。
+// 级别对应关系：Trace、Debug、Info、Warn、Error 对应同名级别，Fatal 和 Panic 对应 xslog.LevelFatal；
+// logrus.ErrorKey 字段中的 error 按 xslog.Err 输出。开启 ReportCaller 时保留 logrus 记录的调用位置
+type Hook struct {
+	handler slog.Handler
+}
+
+// NewHook 返回写入 logger 的 Hook
+func NewHook(logger *xslog.Logger) *Hook {
+	return &Hook{handler: logger.Handler()}
+}
+
+// Install 把 l 的输出整体交给 logger：添加 Hook，丢弃 logrus 自己的输出，
+// 并把 l 的级别放开到 Trace，由 logger 的级别配置决定记录是否输出
+func Install(l *logrus.Logger, logger *xslog.Logger) {
+	l.AddHook(NewHook(logger))
+	l.SetOutput(io.Discard)
+	l.SetFormatter(discardFormatter{})
+	l.SetLevel(logrus.TraceLevel)
+}
+
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := slogLevel(entry.Level)
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+	var pc uintptr
+	if entry.Caller != nil {
+		pc = entry.Caller.PC
+	}
+	r := slog.NewRecord(entry.Time, level, entry.Message, pc)
+	// logrus.Fields 是 map，按键名排序保证输出顺序稳定
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := entry.Data[k]
+		if err, ok := v.(error); ok && k == logrus.ErrorKey {
+			r.AddAttrs(xslog.Err(err))
+			continue
+		}
+		r.AddAttrs(slog.Any(k, v))
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func slogLevel(level logrus.Level) slog.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return xslog.LevelTrace
+	case logrus.DebugLevel:
+		return slog.LevelDebug
+	case logrus.InfoLevel:
+		return slog.LevelInfo
+	case logrus.WarnLevel:
+		return slog.LevelWarn
+	case logrus.ErrorLevel:
+		return slog.LevelError
+	}
+	return xslog.LevelFatal
+}
+
+// discardFormatter 让 Install 之后的 logrus 不再格式化记录
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
@@ -0,0 +1,81 @@
+// Package xslogprom 以 Prometheus 指标导出 xslog 的记录数、写入错误、队列长度和写入延迟
+package xslogprom
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xbfding/xslog"
+)
+
+// Collector 是导出以下指标的 prometheus.Collector：
+//
+//	log_records_total{level,sink}         各输出成功写入的记录数
+//	log_write_errors_total{sink}          各输出写入失败的次数
+//	log_dropped_total{reason}             丢弃的记录数，reason 见 xslog.DropSampled 等
+//	log_queue_depth                       异步队列中等待写出的记录数
+//	log_write_duration_seconds{sink}      每条记录的写入耗时
+//
+// 例如 rate(log_records_total{level="ERROR"}[5m]) 可以用于错误日志突增的告警
+type Collector struct {
+	logger   *xslog.Logger
+	records  *prometheus.Desc
+	errors   *prometheus.Desc
+	dropped  *prometheus.Desc
+	queue    *prometheus.Desc
+	duration *prometheus.HistogramVec
+}
+
+// DefaultBuckets 是 log_write_duration_seconds 的默认分桶，从 10µs 到约 2.6s
+var DefaultBuckets = prometheus.ExponentialBuckets(10e-6, 4, 10)
+
+// NewCollector 返回导出 logger 指标的 Collector。写入延迟通过 logger.OnWrite 统计，
+// 会替换之前设置的 OnWrite 回调
+//
+//	prometheus.MustRegister(xslogprom.NewCollector(logger))
+func NewCollector(logger *xslog.Logger) *Collector {
+	c := &Collector{
+		logger: logger,
+		records: prometheus.NewDesc("log_records_total",
+			"Number of log records written, by level and sink.", []string{"level", "sink"}, nil),
+		errors: prometheus.NewDesc("log_write_errors_total",
+			"Number of failed log writes, by sink.", []string{"sink"}, nil),
+		dropped: prometheus.NewDesc("log_dropped_total",
+			"Number of log records dropped before reaching any sink, by reason.", []string{"reason"}, nil),
+		queue: prometheus.NewDesc("log_queue_depth",
+			"Number of log records waiting in async queues.", nil, nil),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "log_write_duration_seconds",
+			Help:    "Time taken to write a log record to a sink.",
+			Buckets: DefaultBuckets,
+		}, []string{"sink"}),
+	}
+	logger.OnWrite(func(sink string, level slog.Level, d time.Duration, err error) {
+		c.duration.WithLabelValues(sink).Observe(d.Seconds())
+	})
+	return c
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.records
+	ch <- c.errors
+	ch <- c.dropped
+	ch <- c.queue
+	c.duration.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	st := c.logger.Stats()
+	for sink, s := range st.Sinks {
+		for level, n := range s.Written {
+			ch <- prometheus.MustNewConstMetric(c.records, prometheus.CounterValue, float64(n), level, sink)
+		}
+		ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(s.Errors), sink)
+	}
+	for reason, n := range st.Dropped {
+		ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(n), reason)
+	}
+	ch <- prometheus.MustNewConstMetric(c.queue, prometheus.GaugeValue, float64(c.logger.QueueDepth()))
+	c.duration.Collect(ch)
+}
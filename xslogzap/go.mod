@@ -0,0 +1,19 @@
+module github.com/xbfding/xslog/xslogzap
+
+go 1.21.12
+
+require (
+	github.com/xbfding/xslog v0.0.0
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/xbfding/xslog => ../
@@ -0,0 +1,167 @@
+// Package xslogzap 提供写入 xslog 的 zapcore.Core，
+// 让内部使用 zap 的服务与 xslog 共用同一组文件、轮转和远程输出配置
+package xslogzap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/xbfding/xslog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// New 返回写入 logger 的 *zap.Logger
+func New(logger *xslog.Logger, opts ...zap.Option) *zap.Logger {
+	return zap.New(NewCore(logger), opts...)
+}
+
+// Core 把 zap 的记录交给 xslog.Logger，级别、开关和覆盖规则都由 logger 决定。
+// DPanic、Panic 和 Fatal 对应 xslog.LevelFatal；zap 的 logger 名称通过 xslog.Logger.Named 附加，
+// zap.Namespace 之后的字段放进同名分组
+type Core struct {
+	logger *xslog.Logger
+}
+
+// NewCore 返回写入 logger 的 Core
+func NewCore(logger *xslog.Logger) *Core {
+	return &Core{logger: logger}
+}
+
+var _ zapcore.Core = (*Core)(nil)
+
+func (c *Core) Enabled(level zapcore.Level) bool {
+	return c.logger.Enabled(context.Background(), slogLevel(level))
+}
+
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	logger := c.logger
+	for i, f := range fields {
+		// Namespace 之后的字段（包括之后 With 的字段）都在这个分组中
+		if f.Type == zapcore.NamespaceType {
+			logger = logger.With(attrsToArgs(convertFields(fields[:i]))...).WithGroup(f.Key)
+			return (&Core{logger: logger}).With(fields[i+1:])
+		}
+	}
+	if attrs := convertFields(fields); len(attrs) > 0 {
+		logger = logger.With(attrsToArgs(attrs)...)
+	}
+	return &Core{logger: logger}
+}
+
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var pc uintptr
+	if ent.Caller.Defined {
+		pc = ent.Caller.PC
+	}
+	r := slog.NewRecord(ent.Time, slogLevel(ent.Level), ent.Message, pc)
+	r.AddAttrs(convertFields(fields)...)
+	if ent.Stack != "" {
+		r.AddAttrs(slog.String(xslog.StackKey, ent.Stack))
+	}
+	return c.logger.Named(ent.LoggerName).Handler().Handle(context.Background(), r)
+}
+
+func (c *Core) Sync() error {
+	return c.logger.Sync()
+}
+
+func slogLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	case level == zapcore.ErrorLevel:
+		return slog.LevelError
+	}
+	return xslog.LevelFatal
+}
+
+// convertFields 把 zap 字段转为 slog 属性，Namespace 之后的字段放进分组
+func convertFields(fields []zapcore.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.SkipType:
+			continue
+		case zapcore.NamespaceType:
+			if rest := convertFields(fields[i+1:]); len(rest) > 0 {
+				attrs = append(attrs, slog.Attr{Key: f.Key, Value: slog.GroupValue(rest...)})
+			}
+			return attrs
+		}
+		attrs = append(attrs, convertField(f)...)
+	}
+	return attrs
+}
+
+func convertField(f zapcore.Field) []slog.Attr {
+	switch f.Type {
+	case zapcore.StringType:
+		return []slog.Attr{slog.String(f.Key, f.String)}
+	case zapcore.BoolType:
+		return []slog.Attr{slog.Bool(f.Key, f.Integer == 1)}
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return []slog.Attr{slog.Int64(f.Key, f.Integer)}
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return []slog.Attr{slog.Uint64(f.Key, uint64(f.Integer))}
+	case zapcore.Float64Type:
+		return []slog.Attr{slog.Float64(f.Key, math.Float64frombits(uint64(f.Integer)))}
+	case zapcore.Float32Type:
+		return []slog.Attr{slog.Float64(f.Key, float64(math.Float32frombits(uint32(f.Integer))))}
+	case zapcore.DurationType:
+		return []slog.Attr{slog.Duration(f.Key, time.Duration(f.Integer))}
+	case zapcore.TimeType:
+		t := time.Unix(0, f.Integer)
+		if loc, ok := f.Interface.(*time.Location); ok {
+			t = t.In(loc)
+		}
+		return []slog.Attr{slog.Time(f.Key, t)}
+	case zapcore.TimeFullType:
+		if t, ok := f.Interface.(time.Time); ok {
+			return []slog.Attr{slog.Time(f.Key, t)}
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			if f.Key == xslog.ErrorKey {
+				return []slog.Attr{xslog.Err(err)}
+			}
+			return []slog.Attr{slog.String(f.Key, err.Error())}
+		}
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok {
+			return []slog.Attr{slog.String(f.Key, s.String())}
+		}
+	case zapcore.ReflectType:
+		return []slog.Attr{slog.Any(f.Key, f.Interface)}
+	}
+	// 其余类型（对象、数组、二进制等）交给 zap 自己编码
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	attrs := make([]slog.Attr, 0, len(enc.Fields))
+	for k, v := range enc.Fields {
+		attrs = append(attrs, slog.Any(k, v))
+	}
+	return attrs
+}
+
+func attrsToArgs(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}